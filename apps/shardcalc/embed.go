@@ -0,0 +1,22 @@
+//go:build embedui
+
+// Package shardcalc embeds the built shard calculator. Run npm run build before building with the
+// embedui tag.
+package shardcalc
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// UI returns the built app, or nil if the binary was built without it
+func UI() fs.FS {
+	ui, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil
+	}
+	return ui
+}
@@ -0,0 +1,10 @@
+//go:build !embedui
+
+package shardcalc
+
+import "io/fs"
+
+// UI returns the built app, or nil if the binary was built without it
+func UI() fs.FS {
+	return nil
+}
@@ -1,12 +1,11 @@
 import type { ShardView } from "./view";
 import { A } from "@solidjs/router";
-import { formatNumber, formatEffect, formatSource, getFusionTypeIcon, capitalize } from "./format";
+import { formatNumber, formatPrice, formatEffect, formatSource, getFusionTypeIcon, capitalize } from "./format";
 import { getCoinStack, shardImgPath } from "./assetPaths";
 import { useContext } from "solid-js";
 import { AppContext } from "./appContext";
 
 export function ShardDetails(props: { shard: ShardView }) {
-  const rarityClass = () => props.shard.rarity;
   const { vm } = useContext(AppContext);
 
   return (
@@ -17,7 +16,7 @@ export function ShardDetails(props: { shard: ShardView }) {
         </div>
         <div class="column shard-detail-summary">
           <div class="row shard-detail-line">
-            <span class={["shard-detail-name", rarityClass()].join(" ")}>
+            <span class="shard-detail-name" style={{ color: props.shard.rarityColor }}>
               {props.shard.id} {props.shard.name}
             </span>
             <span class="shard-rarity">{capitalize(`${props.shard.rarity} ${props.shard.category} shard`)}</span>
@@ -31,10 +30,16 @@ export function ShardDetails(props: { shard: ShardView }) {
           <span class="shard-skill">Shards required to max: {props.shard.costToMax}</span>
           <div class="row">
             <div class="coin-stack-wrap">
-              <img class="coin-stack" src={getCoinStack(props.shard.bazaarPrice)} />
+              {props.shard.bazaarPrice !== undefined && (
+                <img class="coin-stack" src={getCoinStack(props.shard.bazaarPrice)} />
+              )}
             </div>
             <div class="shard-price">
-              <span class="shard-price-number">{formatNumber(props.shard.bazaarPrice)}</span>
+              <span class="shard-price-number">{formatPrice(props.shard.bazaarPrice)}</span>
+              {props.shard.priceEstimated && <span class="shard-price-estimated"> (estimated)</span>}
+              {props.shard.priceSource && props.shard.priceSource !== "bazaar" && (
+                <span class="shard-price-estimated"> (from {props.shard.priceSource})</span>
+              )}
             </div>
           </div>
         </div>
@@ -43,9 +48,11 @@ export function ShardDetails(props: { shard: ShardView }) {
         <div class="column">
           <span>Basic fuse target: {props.shard.basicFuseTarget || "NONE"}</span>
         </div>
-        <div class="column">
-          <span>Chameleon targets: {props.shard.chameleonTargets && props.shard.chameleonTargets.join(", ")}</span>
-        </div>
+        {Object.entries(props.shard.wildcardTargets).map(([fuseType, targets]) => (
+          <div class="column">
+            <span>{fuseType.charAt(0).toUpperCase() + fuseType.slice(1)} targets: {targets.join(", ")}</span>
+          </div>
+        ))}
       </div>
       <div class="row">
         <div class="column">
@@ -84,8 +91,10 @@ export function ShardDetails(props: { shard: ShardView }) {
             </thead>
             <tbody>
               {props.shard.valuatedFuses.map(function (fuse, i) {
-                const efficiency = props.shard.bazaarPrice / fuse.bazaarPricePerShard;
-                const profit = (props.shard.bazaarPrice - fuse.bazaarPricePerShard) * fuse.multiplier;
+                // An unpriced target still lists its fusions, just without comparing to its price
+                const price = props.shard.bazaarPrice;
+                const efficiency = price === undefined ? undefined : price / fuse.bazaarPricePerShard;
+                const profit = price === undefined ? 0 : (price - fuse.bazaarPricePerShard) * fuse.multiplier;
                 const shard1CostToMax = (props.shard.costToMax * fuse.shard1Cost) / fuse.multiplier;
                 const shard2CostToMax = (props.shard.costToMax * fuse.shard2Cost) / fuse.multiplier;
                 return (
@@ -104,7 +113,7 @@ export function ShardDetails(props: { shard: ShardView }) {
                     </A>
                     <td>{shard2CostToMax}</td>
                     <td>{formatNumber(fuse.bazaarPricePerShard)}</td>
-                    <td>{efficiency.toFixed(3)}</td>
+                    <td>{efficiency === undefined ? "" : efficiency.toFixed(3)}</td>
                     <td>{profit > 0 ? formatNumber(profit) : ""}</td>
                     <td>{fuse.swappable ? "🔄" : ""}</td>
                     <td>{getFusionTypeIcon(fuse.fuseType)}</td>
@@ -1,10 +1,9 @@
 import type { ShardView } from "./view";
 import { A } from "@solidjs/router";
-import { formatNumber } from "./format";
+import { formatPrice } from "./format";
 import { getCoinStack, shardSmallImgPath } from "./assetPaths";
 
 export function ShardOption(props: { shard: ShardView }) {
-  const rarityClass = props.shard.rarity;
   return (
     <A href={`/${props.shard.id}`} activeClass="active-shard-option">
       <div class="shard-option row">
@@ -14,7 +13,7 @@ export function ShardOption(props: { shard: ShardView }) {
               <img class="shard-image" src={shardSmallImgPath(props.shard.id)} />
             </div>
             <div class="column">
-              <div class={["shard-name", rarityClass].join(" ")}>
+              <div class="shard-name" style={{ color: props.shard.rarityColor }}>
                 {props.shard.id} {props.shard.name}
               </div>
               <div class="shard-attribute">{props.shard.attributeName}</div>
@@ -24,10 +23,13 @@ export function ShardOption(props: { shard: ShardView }) {
         <div class="column">
           <div class="row">
             <div class="column">
-              <span class="shard-price">{formatNumber(props.shard.bazaarPrice)}</span>
+              <span class="shard-price">{formatPrice(props.shard.bazaarPrice)}</span>
+              {props.shard.priceEstimated && <span class="shard-price-estimated">(estimated)</span>}
             </div>
             <div class="column coin-stack-wrap">
-              <img class="coin-stack" src={getCoinStack(props.shard.bazaarPrice)} />
+              {props.shard.bazaarPrice !== undefined && (
+                <img class="coin-stack" src={getCoinStack(props.shard.bazaarPrice)} />
+              )}
             </div>
           </div>
         </div>
@@ -7,6 +7,7 @@ export function Stats() {
   return (
     <div>
       Cost to max everything: <span class="cost">{formatNumber(vm.stats.totalPriceToMax)}</span>
+      {vm.stats.unpricedShards > 0 && <span>, not counting {vm.stats.unpricedShards} unpriced shards</span>}
     </div>
   );
 }
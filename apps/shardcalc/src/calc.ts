@@ -57,14 +57,12 @@ function calculateValuatedFusesByTarget(db: ShardDatabase): Record<string, Valua
         if (result.id === id1 || result.id === id2) {
           continue;
         }
-        const bazaarPrice1 = db.prices[s1.bazaarId];
-        if (!bazaarPrice1) {
-          throw new Error(`Missing bazaar price for shard ${s1.id} (bazaar ID ${s1.bazaarId})`);
-        }
+        // Fusions with an unpriced input can't be valued, so they're left out rather than guessed
         const s2 = db.shards[id2];
+        const bazaarPrice1 = db.prices[s1.bazaarId];
         const bazaarPrice2 = db.prices[s2.bazaarId];
-        if (!bazaarPrice2) {
-          throw new Error(`Missing bazaar price for shard ${s2.id} (bazaar ID ${s2.bazaarId})`);
+        if (!bazaarPrice1 || !bazaarPrice2) {
+          continue;
         }
 
         const dupeId = [s1.id, s2.id].sort().join("-");
@@ -108,21 +106,22 @@ function calculateValuatedRequirementInfo(db: ShardDatabase): Record<string, Val
 
   for (const key in db.specialRequirementInfo) {
     const info = db.specialRequirementInfo[key];
-    const matchCosts: ShardCost[] = info.matches.map((match) => {
+    const matchCosts: ShardCost[] = [];
+    for (const match of info.matches) {
       const shard = db.shards[match];
       if (!shard) {
         throw new Error(`Invalid shard match ${match} in requirement info`);
       }
       const bazaarPrice = db.prices[shard.bazaarId];
       if (bazaarPrice === undefined) {
-        throw new Error(`Missing bazaar price for shard ${shard.id} (bazaar ID ${shard.bazaarId})`);
+        continue;
       }
-      return {
+      matchCosts.push({
         shardId: match,
         shardName: shard.name,
         cost: bazaarPrice,
-      };
-    });
+      });
+    }
 
     matchCosts.sort((a, b) => a.cost - b.cost);
 
@@ -279,16 +278,19 @@ function getSortedContributionsByComponent(
   return contributionsByComponent;
 }
 
-function getTotalPriceToMax(db: ShardDatabase) {
+// Unpriced shards are counted rather than priced, so the total is a lower bound when there are any
+function getTotalPriceToMax(db: ShardDatabase): { totalPrice: number; unpriced: number } {
   let totalPrice = 0;
+  let unpriced = 0;
   for (const shard of Object.values(db.shards)) {
     const bazaarPrice = db.prices[shard.bazaarId];
     if (bazaarPrice === undefined) {
-      throw new Error(`Missing bazaar price for shard ${shard.id} (bazaar ID ${shard.bazaarId})`);
+      unpriced++;
+      continue;
     }
     totalPrice += bazaarPrice * db.costToMax[shard.rarity];
   }
-  return totalPrice;
+  return { totalPrice, unpriced };
 }
 
 export interface ShardCalc {
@@ -298,6 +300,7 @@ export interface ShardCalc {
   sortedContributionsByTarget: Record<string, ShardContribution[]>;
   sortedContributionsByComponent: Record<string, ShardContribution[]>;
   totalPriceToMax: number;
+  unpricedShards: number;
 }
 
 export function getShardCalc(): ShardCalc {
@@ -307,7 +310,7 @@ export function getShardCalc(): ShardCalc {
   const marginalContributions = calculateContributions(valuatedFusesByTarget);
   const sortedContributionsByTarget = getSortedContributionsByTarget(marginalContributions);
   const sortedContributionsByComponent = getSortedContributionsByComponent(marginalContributions);
-  const totalPriceToMax = getTotalPriceToMax(db);
+  const { totalPrice, unpriced } = getTotalPriceToMax(db);
 
   return {
     db: db,
@@ -315,6 +318,7 @@ export function getShardCalc(): ShardCalc {
     valuatedRequirementInfo: valuatedRequirementInfo,
     sortedContributionsByTarget: sortedContributionsByTarget,
     sortedContributionsByComponent: sortedContributionsByComponent,
-    totalPriceToMax: totalPriceToMax,
+    totalPriceToMax: totalPrice,
+    unpricedShards: unpriced,
   };
 }
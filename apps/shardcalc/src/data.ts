@@ -1,6 +1,3 @@
-import shardsProcessedJson from "../../../data/shards_processed.json";
-import priceDataJson from "../../../data/shard_prices.json";
-
 interface FuseResult {
   type: string;
   id: string;
@@ -20,6 +17,16 @@ export interface Source {
   sourceDesc: string;
 }
 
+export interface EffectStat {
+  stat: string;
+  unit?: string;
+  condition?: string;
+  skill?: string;
+  value?: string;
+  scaling?: string;
+  max?: number;
+}
+
 export interface Shard {
   id: string;
   bazaarId: string;
@@ -30,6 +37,7 @@ export interface Shard {
   effectDescription: string;
   effectMax: number;
   effect2Max?: number;
+  stats?: EffectStat[];
   effectTags?: Record<string, boolean>;
   category: string;
   skill: string;
@@ -38,7 +46,7 @@ export interface Shard {
   sources?: Source[];
   specialFusesDesc?: string[][];
   basicFuseTarget: string;
-  chameleonTargets: string[];
+  wildcardTargets?: Record<string, string[]>;
   fuseCombinations: Record<string, FuseCombination>;
 }
 
@@ -48,11 +56,26 @@ export interface RequirementInfo {
   description: string;
 }
 
+export interface RarityDef {
+  id: string;
+  prefix: string;
+  name: string;
+  color?: string;
+}
+
+export interface CategoryDef {
+  id: string;
+  name: string;
+}
+
 interface ShardsProcessed {
+  rarities: RarityDef[];
+  categories: CategoryDef[];
   familyShards: Record<string, string[]>;
   categoryShards: Record<string, string[]>;
   skillShards: Record<string, string[]>;
   tagShards: Record<string, string[]>;
+  statShards: Record<string, string[]>;
   sourceTypeShards: Record<string, string[]>;
   rarityShards: Record<string, string[]>;
   costToMax: Record<string, number>;
@@ -61,12 +84,25 @@ interface ShardsProcessed {
   specialRequirementInfo: Record<string, RequirementInfo>;
 }
 
+export interface ShardPrice {
+  price: number;
+  estimated: boolean;
+  method?: string;
+  // Where a quoted price came from: "bazaar", "auctions", "file", ...
+  source?: string;
+}
+
 interface PriceData {
   timestamp: number;
+  // Every quoted price, from the bazaar or wherever else the server got prices
   shardPrices: Record<string, number>;
+  // Quotes with their source, plus estimates for shards without a quote
+  prices?: Record<string, ShardPrice>;
 }
 
 export interface ShardDatabase {
+  // Lowest rarity first
+  rarities: RarityDef[];
   familyGroups: Record<string, string[]>;
   categoryGroups: Record<string, string[]>;
   skillGroups: Record<string, string[]>;
@@ -78,12 +114,48 @@ export interface ShardDatabase {
   specialRequirementList: string[];
   specialRequirementInfo: Record<string, RequirementInfo>;
   priceTimestamp: number;
+  // Keyed by bazaar ID. Shards without a price are shown unpriced.
   prices: Record<string, number>;
+  // Also keyed by bazaar ID, true for prices estimated rather than quoted
+  estimatedPrices: Record<string, boolean>;
+  priceSources: Record<string, string>;
+}
+
+// Shard data comes from the server so the app always matches the data it was started with. Without
+// one, like under plain `vite dev` or on a static host, the copy bundled at build time is used.
+async function fetchShardData(): Promise<ShardsProcessed> {
+  try {
+    const res = await fetch("/api/shards");
+    if (!res.ok) {
+      throw new Error(`status ${res.status}`);
+    }
+    return (await res.json()) as ShardsProcessed;
+  } catch (err) {
+    console.warn("Using the bundled shard data:", err);
+    return (await import("../../../data/shards_processed.json")).default as ShardsProcessed;
+  }
 }
+
+// Prices come from the server so they stay live. Until it has fetched some, or without a server at
+// all, every shard is shown unpriced and fusions are listed without costs.
+async function fetchPriceData(): Promise<PriceData> {
+  try {
+    const res = await fetch("/api/prices");
+    if (!res.ok) {
+      throw new Error(`status ${res.status}`);
+    }
+    return (await res.json()) as PriceData;
+  } catch (err) {
+    console.error("Failed to load prices:", err);
+    return { timestamp: 0, shardPrices: {} };
+  }
+}
+
+const [shardsProcessed, priceData] = await Promise.all([fetchShardData(), fetchPriceData()]);
+
 export function loadData(): ShardDatabase {
-  const shardsProcessed = shardsProcessedJson as ShardsProcessed;
-  const priceData = priceDataJson as PriceData;
   return {
+    rarities: shardsProcessed.rarities,
     familyGroups: shardsProcessed.familyShards as Record<string, string[]>,
     categoryGroups: shardsProcessed.categoryShards as Record<string, string[]>,
     skillGroups: shardsProcessed.skillShards as Record<string, string[]>,
@@ -95,6 +167,16 @@ export function loadData(): ShardDatabase {
     specialRequirementList: shardsProcessed.specialRequirementList as string[],
     specialRequirementInfo: shardsProcessed.specialRequirementInfo as Record<string, RequirementInfo>,
     priceTimestamp: priceData.timestamp,
-    prices: priceData.shardPrices as Record<string, number>,
+    prices: priceData.prices
+      ? Object.fromEntries(Object.entries(priceData.prices).map(([id, p]) => [id, p.price]))
+      : priceData.shardPrices,
+    estimatedPrices: Object.fromEntries(
+      Object.entries(priceData.prices ?? {}).map(([id, p]) => [id, p.estimated])
+    ),
+    priceSources: Object.fromEntries(
+      Object.entries(priceData.prices ?? {})
+        .filter(([, p]) => p.source)
+        .map(([id, p]) => [id, p.source as string])
+    ),
   };
 }
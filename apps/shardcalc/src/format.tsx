@@ -16,6 +16,10 @@ export function formatNumber(number: number): string {
   }
 }
 
+export function formatPrice(price: number | undefined): string {
+  return price === undefined ? "Unpriced" : formatNumber(price);
+}
+
 export function capitalize(text: string): string {
   return text.charAt(0).toUpperCase() + text.slice(1);
 }
@@ -2,23 +2,24 @@ import { getShardCalc } from "./calc";
 import type { ShardCalc, ValuatedFuseResult, ShardContribution } from "./calc";
 import type { Source } from "./data";
 
-function getBazaarPrice(id: string, calc: ShardCalc): number {
+// Undefined for shards with no price, which are shown unpriced
+function getBazaarPrice(id: string, calc: ShardCalc): number | undefined {
   const shard = calc.db.shards[id];
   if (!shard) {
     throw new Error(`Shard with ID ${id} not found in database`);
   }
-  const bazaarPrice = calc.db.prices[shard.bazaarId];
-  if (bazaarPrice === undefined) {
-    throw new Error(`Missing bazaar price for shard ${shard.id} (bazaar ID ${shard.bazaarId})`);
-  }
-  return bazaarPrice;
+  return calc.db.prices[shard.bazaarId];
 }
 
 export interface ShardView {
   id: string;
-  bazaarPrice: number;
+  bazaarPrice?: number;
+  // Estimated from other shards because the bazaar doesn't list this one
+  priceEstimated: boolean;
+  priceSource?: string;
   name: string;
   rarity: string;
+  rarityColor?: string;
   number: number;
   attributeName: string;
   effectDescription: string;
@@ -32,7 +33,7 @@ export interface ShardView {
   sources?: Source[];
   specialFusesDesc?: string[][];
   basicFuseTarget: string;
-  chameleonTargets: string[];
+  wildcardTargets: Record<string, string[]>;
   valuatedFuses: ValuatedFuseResult[];
   costToMax: number;
   marginalContributionsToThis: ShardContribution[];
@@ -47,8 +48,11 @@ function getShardView(id: string, calc: ShardCalc): ShardView {
   return {
     id: id,
     bazaarPrice: getBazaarPrice(id, calc),
+    priceEstimated: calc.db.estimatedPrices[shard.bazaarId] ?? false,
+    priceSource: calc.db.priceSources[shard.bazaarId],
     name: shard.name,
     rarity: shard.rarity,
+    rarityColor: calc.db.rarities.find((r) => r.id === shard.rarity)?.color,
     number: shard.number,
     attributeName: shard.attributeName,
     effectDescription: shard.effectDescription,
@@ -62,7 +66,7 @@ function getShardView(id: string, calc: ShardCalc): ShardView {
     sources: shard.sources,
     specialFusesDesc: shard.specialFusesDesc,
     basicFuseTarget: shard.basicFuseTarget,
-    chameleonTargets: shard.chameleonTargets,
+    wildcardTargets: shard.wildcardTargets || {},
     valuatedFuses: calc.valuatedFusesByTarget[id] || [],
     costToMax: calc.db.costToMax[shard.rarity] || 0,
     marginalContributionsToThis: calc.sortedContributionsByTarget[id] || [],
@@ -81,25 +85,9 @@ function compareShardGroup(a: ShardGroup, b: ShardGroup): number {
   return 0;
 }
 
-function rarityValue(rarity: string): number {
-  switch (rarity) {
-    case "common":
-      return 1;
-    case "uncommon":
-      return 2;
-    case "rare":
-      return 3;
-    case "epic":
-      return 4;
-    case "legendary":
-      return 5;
-    default:
-      return 0;
-  }
-}
-
 interface Stats {
   totalPriceToMax: number;
+  unpricedShards: number;
 }
 
 export interface ShardViewModel {
@@ -117,13 +105,8 @@ export interface ShardViewModel {
 export function getShardViewModel(): ShardViewModel {
   const calc = getShardCalc();
 
-  const shardIds: string[] = [
-    ...calc.db.rarityGroups["common"],
-    ...calc.db.rarityGroups["uncommon"],
-    ...calc.db.rarityGroups["rare"],
-    ...calc.db.rarityGroups["epic"],
-    ...calc.db.rarityGroups["legendary"],
-  ];
+  const rarityIds = calc.db.rarities.map((r) => r.id);
+  const shardIds: string[] = rarityIds.flatMap((id) => calc.db.rarityGroups[id] || []);
 
   const familyGroups: ShardGroup[] = Object.entries(calc.db.familyGroups)
     .map(([name, ids]) => ({
@@ -176,11 +159,12 @@ export function getShardViewModel(): ShardViewModel {
       shardIds: ids,
     }))
     .sort(function (a, b) {
-      return rarityValue(a.groupName) - rarityValue(b.groupName);
+      return rarityIds.indexOf(a.groupName) - rarityIds.indexOf(b.groupName);
     });
 
   const stats: Stats = {
     totalPriceToMax: calc.totalPriceToMax,
+    unpricedShards: calc.unpricedShards,
   };
 
   return {
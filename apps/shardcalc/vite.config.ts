@@ -3,4 +3,8 @@ import solid from 'vite-plugin-solid'
 
 export default defineConfig({
   plugins: [solid()],
+  // Prices are loaded with a top-level await
+  build: { target: 'es2022' },
+  // In development the API comes from skyblock serve running alongside
+  server: { proxy: { '/api': 'http://localhost:8080' } },
 })
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Shard data to convert: a JSON, YAML or TOML file, or a directory of them")
+	out := flag.String("out", "", "Output file, or an existing directory to split the shards into one file per rarity")
+	format := flag.String("format", "", "Output format (json, yaml or toml). Defaults to the extension of -out")
+	expressions := flag.Bool("expressions", false, "Rewrite special fuse requirements as expressions like \"category:forest & rarity:common\"")
+	flag.Parse()
+
+	if *out == "" {
+		cli.Fatal("Error converting shard data", fmt.Errorf("%w: -out is required", cli.ErrUsage))
+	}
+	if *format == "" {
+		if info, err := os.Stat(*out); err == nil && info.IsDir() {
+			cli.Fatal("Error converting shard data", fmt.Errorf("%w: -format is required when -out is a directory", cli.ErrUsage))
+		}
+		detected, err := shards.FormatForPath(*out)
+		if err != nil {
+			cli.Fatal("Error converting shard data", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+		}
+		*format = detected
+	}
+
+	if err := shards.ConvertShardData(*in, *out, shards.ConvertOptions{Format: *format, Expressions: *expressions}); err != nil {
+		cli.Fatal("Error converting shard data", err)
+	}
+	log.Printf("Converted %s to %s", *in, *out)
+}
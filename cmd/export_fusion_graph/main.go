@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	out := flag.String("out", "", "Output file for the graph (default stdout)")
+	format := flag.String("format", "dot", "Output format: dot, gexf or csv")
+	family := flag.String("family", "", "Comma-separated families to include")
+	category := flag.String("category", "", "Comma-separated categories to include")
+	rarity := flag.String("rarity", "", "Comma-separated rarities to include")
+	fuseType := flag.String("type", "", "Comma-separated fuse types to include (chameleon, basic, special)")
+	flag.Parse()
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	graph, err := shardData.FusionGraph(shards.GraphFilter{
+		Families:   splitList(*family),
+		Categories: splitList(*category),
+		Rarities:   splitList(*rarity),
+		Types:      splitList(*fuseType),
+	})
+	if err != nil {
+		cli.Fatal("Error filtering graph", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			cli.Fatal("Error creating output file", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "dot":
+		err = graph.WriteDOT(w)
+	case "gexf":
+		err = graph.WriteGEXF(w)
+	case "csv":
+		err = graph.WriteCSV(w)
+	default:
+		cli.Fatal("Error writing graph", fmt.Errorf("%w: unknown format %s", cli.ErrUsage, *format))
+	}
+	if err != nil {
+		cli.Fatal("Error writing graph", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/bot"
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// priceCache keeps the last prices from source so commands don't wait on a fetch
+type priceCache struct {
+	source prices.PriceProvider
+	mu     sync.RWMutex
+	snap   *prices.Snapshot
+}
+
+func (c *priceCache) Prices(ctx context.Context) (*prices.Snapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.snap == nil {
+		return nil, fmt.Errorf("%w: prices have not been fetched yet", prices.ErrNoPrices)
+	}
+	return c.snap, nil
+}
+
+func (c *priceCache) refresh() {
+	snap, err := c.source.Prices(context.Background())
+	if err != nil {
+		log.Printf("Error getting prices: %v", err)
+		return
+	}
+	c.mu.Lock()
+	c.snap = snap
+	c.mu.Unlock()
+}
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	aliasFile := flag.String("aliases", "", "Optional JSON file of extra shard aliases")
+	addr := flag.String("addr", ":8080", "Address to serve the interactions endpoint on")
+	refresh := flag.Duration("refresh", 5*time.Minute, "Time between price refreshes")
+	priceFlags := cli.AddPriceFlags(flag.CommandLine, "", true)
+	flag.Parse()
+
+	var publicKey ed25519.PublicKey
+	if keyHex := os.Getenv("DISCORD_PUBLIC_KEY"); keyHex != "" {
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			cli.Fatal("Invalid DISCORD_PUBLIC_KEY", fmt.Errorf("%w: expected a hex encoded ed25519 public key", cli.ErrUsage))
+		}
+		publicKey = key
+	} else {
+		log.Printf("DISCORD_PUBLIC_KEY is not set; request signatures will not be checked")
+	}
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	provider, err := priceFlags.Provider(shardData)
+	if err != nil {
+		cli.Fatal("Error choosing prices", err)
+	}
+
+	var aliases map[string]string
+	if *aliasFile != "" {
+		aliases, err = shards.LoadAliases(*aliasFile)
+		if err != nil {
+			cli.Fatal("Error loading aliases", err)
+		}
+	}
+	resolver, err := shards.NewResolver(shardData, aliases)
+	if err != nil {
+		cli.Fatal("Error building shard resolver", err)
+	}
+
+	cache := &priceCache{source: provider}
+	cache.refresh()
+	go func() {
+		for range time.Tick(*refresh) {
+			cache.refresh()
+		}
+	}()
+
+	handler := &bot.InteractionHandler{
+		Router:    bot.NewRouter(shardData, resolver, cache),
+		PublicKey: publicKey,
+	}
+	http.Handle("/interactions", handler)
+	log.Printf("Serving interactions on %s/interactions", *addr)
+	cli.Fatal("Error serving", http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	asJson := flag.Bool("json", false, "Print the full report as JSON")
+	top := flag.Int("top", 15, "Number of hub shards to list")
+	flag.Parse()
+	if *top < 0 {
+		cli.Fatal("Error reading flags", fmt.Errorf("%w: -top can't be negative", cli.ErrUsage))
+	}
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	report := shardData.FusionReport()
+
+	if *asJson {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			cli.Fatal("Error formatting JSON", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	name := func(id string) string {
+		return fmt.Sprintf("%s (%s)", shardData.Shards[id].Name, id)
+	}
+
+	fmt.Println("== Fusion-only shards ==")
+	for _, info := range report.FusionOnly {
+		status := "unreachable"
+		if info.Producible {
+			status = fmt.Sprintf("%d steps", report.MinSteps[info.ID])
+		}
+		fmt.Printf("%-28s %-12s %d inputs, raw materials from: %s\n",
+			name(info.ID), status, len(info.Inputs), strings.Join(info.InputSourceTypes, ", "))
+	}
+
+	fmt.Println("\n== Minimum fusion steps ==")
+	byStep := make(map[int]int)
+	maxStep := 0
+	for _, step := range report.MinSteps {
+		byStep[step]++
+		maxStep = max(maxStep, step)
+	}
+	for step := 0; step <= maxStep; step++ {
+		fmt.Printf("%d: %d shards\n", step, byStep[step])
+	}
+	unreachable := make([]string, 0)
+	for id := range shardData.Shards {
+		if _, ok := report.MinSteps[id]; !ok {
+			unreachable = append(unreachable, id)
+		}
+	}
+	slices.Sort(unreachable)
+	if len(unreachable) > 0 {
+		fmt.Printf("unreachable: %s\n", strings.Join(unreachable, ", "))
+	}
+
+	fmt.Println("\n== Hub shards ==")
+	for _, hub := range report.Hubs[:min(*top, len(report.Hubs))] {
+		fmt.Printf("%-28s feeds %d targets\n", name(hub.ID), hub.Targets)
+	}
+
+	fmt.Println("\n== Fragile targets (single producing pair) ==")
+	for _, f := range report.Fragile {
+		fmt.Printf("%-28s only from %s + %s\n", name(f.ID), name(f.Shard1), name(f.Shard2))
+	}
+}
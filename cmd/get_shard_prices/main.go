@@ -1,18 +1,45 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"os"
+	"log"
 
-	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
 )
 
 func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
 	out := flag.String("out", "data/shard_prices.json", "Output file for shard prices")
+	priceFlags := cli.AddPriceFlags(flag.CommandLine, "", true)
 	flag.Parse()
-	apiKey := os.Getenv("HYPIXEL_API_KEY")
-	if apiKey == "" {
-		panic("HYPIXEL_API_KEY environment variable is not set")
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	provider, err := priceFlags.Provider(shardData)
+	if err != nil {
+		cli.Fatal("Error choosing prices", err)
+	}
+	sheet, err := shardData.LoadPriceSheet(context.Background(), provider)
+	if err != nil {
+		cli.Fatal("Error getting prices", err)
+	}
+	if err := shards.WritePriceSheet(sheet, *out); err != nil {
+		cli.Fatal("Error writing shard prices", err)
+	}
+
+	coverage := sheet.Coverage
+	log.Printf("Wrote %s: %d of %d shards quoted, %d estimated", *out, coverage.Quoted, coverage.Shards, len(coverage.Estimated))
+	if len(coverage.Estimated) > 0 {
+		log.Printf("Estimated from the cheapest fusion: %v", coverage.Estimated)
+	}
+	if len(coverage.Unpriced) > 0 {
+		log.Printf("No price for: %v", coverage.Unpriced)
+	}
+	if len(coverage.Unmatched) > 0 {
+		log.Printf("Quoted but not in the shard data: %v", coverage.Unmatched)
 	}
-	hypixel_api.DumpShardPrices(apiKey, *out)
 }
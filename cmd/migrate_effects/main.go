@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Shard data to migrate: a JSON, YAML or TOML file, or a directory of them")
+	out := flag.String("out", "", "Where to write the shard data with parsed effects. Only reports when empty")
+	format := flag.String("format", "", "Output format (json, yaml or toml). Defaults to the extension of -out")
+	flag.Parse()
+
+	if *out != "" && *format == "" {
+		if info, err := os.Stat(*out); err == nil && info.IsDir() {
+			cli.Fatal("Error migrating effects", fmt.Errorf("%w: -format is required when -out is a directory", cli.ErrUsage))
+		}
+		detected, err := shards.FormatForPath(*out)
+		if err != nil {
+			cli.Fatal("Error migrating effects", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+		}
+		*format = detected
+	}
+
+	migration, err := shards.MigrateEffects(*in, *out, *format)
+	if err != nil {
+		cli.Fatal("Error migrating effects", err)
+	}
+
+	fmt.Printf("%d shards already list their effects, %d parsed, %d need review\n",
+		len(migration.Structured), len(migration.Migrated), len(migration.NeedsReview))
+	for _, review := range migration.NeedsReview {
+		fmt.Printf("%-5s %s\n", review.ID, review.Description)
+	}
+	if *out != "" {
+		log.Printf("Wrote %s", *out)
+	}
+}
@@ -2,13 +2,128 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
 	"github.com/andu2/andu-skyblock-tools/pkg/shards"
 )
 
 func main() {
-	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	in := flag.String("in", "data/shards.json", "Input shard data: a JSON, YAML or TOML file, or a directory of them")
 	out := flag.String("out", "data/shards_processed.json", "Output file for processed shard data")
+	watch := flag.Bool("watch", false, "Keep running and regenerate the output whenever the input changes")
+	poll := flag.Duration("poll", 500*time.Millisecond, "How often to check the input for changes in watch mode")
+	maxPairs := flag.Int("pairs", 10, "Number of changed fusion pairs to list per update in watch mode")
+	workers := flag.Int("workers", 0, "Goroutines computing fusion combinations, 0 for one per CPU")
+	check := flag.Bool("check", false, "Only check that the output and its manifest are up to date with the input")
+	layout := flag.String("layout", shards.LayoutSingle, "Output layout: single, or split for an index plus one fusion chunk per shard")
+	encoding := flag.String("encoding", shards.EncodingFull, "Fusion combination encoding: full, or compact for interned columns")
+	format := flag.String("format", shards.FormatJSON, "Output format: json or msgpack")
 	flag.Parse()
-	shards.DumpShardData(*in, *out)
+	if *maxPairs < 0 {
+		cli.Fatal("Error reading flags", fmt.Errorf("%w: -pairs can't be negative", cli.ErrUsage))
+	}
+	outputOpts := shards.OutputOptions{Layout: *layout, Encoding: *encoding, Format: *format}
+
+	if *check {
+		manifest, err := shards.CheckShardData(*in, *out)
+		if err != nil {
+			cli.Fatal("Processed shard data is out of date", err)
+		}
+		log.Printf("%s is up to date (schema %d, %s)", *out, manifest.SchemaVersion, manifest.Generator)
+		return
+	}
+
+	processOpts := shards.ProcessOptions{Workers: *workers}
+	if !*watch {
+		data, err := shards.ProcessShardsWithOptions(*in, processOpts)
+		if err != nil {
+			cli.Fatal("Error processing shard data", err)
+		}
+		if err := shards.WriteShardData(data, *out, outputOpts); err != nil {
+			cli.Fatal("Error writing processed data", err)
+		}
+		return
+	}
+
+	processor, err := shards.NewIncrementalProcessorWithOptions(*in, processOpts)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	if err := shards.WriteShardData(processor.Data(), *out, outputOpts); err != nil {
+		cli.Fatal("Error writing processed data", err)
+	}
+	log.Printf("Wrote %s, watching %s for changes", *out, *in)
+
+	lastMod := modTime(*in)
+	for range time.Tick(*poll) {
+		mod := modTime(*in)
+		if mod.IsZero() || mod.Equal(lastMod) {
+			continue
+		}
+		lastMod = mod
+
+		changes, err := processor.Reload(*in)
+		if err != nil {
+			log.Printf("Error in %s, keeping previous output: %v", *in, err)
+			continue
+		}
+		if changes.IsEmpty() {
+			log.Printf("No changes in processed data")
+			continue
+		}
+		if err := shards.WriteShardData(processor.Data(), *out, outputOpts); err != nil {
+			log.Printf("Error writing processed data: %v", err)
+			continue
+		}
+		log.Printf("Wrote %s: %s", *out, summarize(processor.Data(), changes, *maxPairs))
+	}
+}
+
+// modTime is the latest modification time of the input, or of any file in it if it's a directory
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	latest := info.ModTime()
+	if info.IsDir() {
+		entries, _ := os.ReadDir(path)
+		for _, entry := range entries {
+			if entryInfo, err := entry.Info(); err == nil && entryInfo.ModTime().After(latest) {
+				latest = entryInfo.ModTime()
+			}
+		}
+	}
+	return latest
+}
+
+func summarize(data *shards.ProcessedShardData, changes *shards.ChangeSet, maxPairs int) string {
+	var sb strings.Builder
+	if changes.FullRebuild {
+		sb.WriteString("full rebuild, ")
+	}
+	fmt.Fprintf(&sb, "%d shards edited, %d fusion pairs changed, %d requirements changed",
+		len(changes.Edited), len(changes.Combinations), len(changes.Requirements))
+	if len(changes.Edited) > 0 {
+		fmt.Fprintf(&sb, "\n  edited: %s", strings.Join(changes.Edited, ", "))
+	}
+	for _, pair := range changes.Combinations[:min(maxPairs, len(changes.Combinations))] {
+		fmt.Fprintf(&sb, "\n  %s + %s", shardName(data, pair.Shard1), shardName(data, pair.Shard2))
+	}
+	if len(changes.Combinations) > maxPairs {
+		fmt.Fprintf(&sb, "\n  ... and %d more pairs", len(changes.Combinations)-maxPairs)
+	}
+	return sb.String()
+}
+
+func shardName(data *shards.ProcessedShardData, id string) string {
+	if shard, ok := data.Shards[id]; ok {
+		return fmt.Sprintf("%s (%s)", shard.Name, id)
+	}
+	return id
 }
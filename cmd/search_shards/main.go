@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/search"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	top := flag.Int("top", 20, "Number of results to list, 0 for all")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), `Usage: search_shards [flags] query
+
+Filters: id, skill, category, rarity, family, tag, source and stat, written as field:value.
+Rarity can also be compared, as in rarity>=rare. Commas separate alternatives, a leading -
+negates, and anything else is searched for in names, attributes, effects and sources.
+
+Example: search_shards 'skill:fishing "sea creature" rarity>=rare source:net'`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	query := strings.Join(flag.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		flag.Usage()
+		cli.Fatal("Error searching", fmt.Errorf("%w: a query is required", cli.ErrUsage))
+	}
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	results, err := search.NewIndex(shardData).Search(query)
+	if err != nil {
+		cli.Fatal("Error searching", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+	}
+
+	if *top > 0 && len(results) > *top {
+		results = results[:*top]
+	}
+	for _, r := range results {
+		s := r.Shard
+		fmt.Printf("%-5s %-18s %-10s %-24s %s\n", s.ID, s.Name, s.Rarity, s.AttributeName,
+			strings.TrimSpace(s.RenderEffect(shards.MaxAttributeLevel)))
+	}
+	if len(results) == 0 {
+		fmt.Println("No shards match")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	priceFlags := cli.AddPriceFlags(flag.CommandLine, "data/shard_prices.json", false)
+	shardQuery := flag.String("shard", "", "Show every attribute level of this shard instead of ranking upgrades")
+	levelsFlag := flag.String("levels", "", "Current attribute levels as shard=level pairs, e.g. C1=3,Grove=10")
+	tag := flag.String("tag", "", "Only rank shards with this effect tag")
+	top := flag.Int("top", 20, "Number of upgrades to list")
+	flag.Parse()
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+
+	resolver, err := shards.NewResolver(shardData, nil)
+	if err != nil {
+		cli.Fatal("Error building shard resolver", err)
+	}
+
+	if *shardQuery != "" {
+		s, err := resolver.ResolveOne(*shardQuery)
+		if err != nil {
+			cli.Fatal("Error finding shard", err)
+		}
+		levels, err := shardData.AttributeLevels(s.ID)
+		if err != nil {
+			cli.Fatal("Error computing levels", err)
+		}
+		fmt.Printf("%s (%s), %s %s\n", s.Name, s.ID, s.Rarity, s.AttributeName)
+		for _, level := range levels {
+			fmt.Printf("%2d  %3d shards (%3d total)  %s\n", level.Level, level.Shards, level.TotalShards, level.Description)
+		}
+		return
+	}
+
+	levels, err := shards.ParseLevels(*levelsFlag, resolver)
+	if err != nil {
+		cli.Fatal("Error reading levels", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+	}
+	provider, err := priceFlags.Provider(shardData)
+	if err != nil {
+		cli.Fatal("Error choosing prices", err)
+	}
+	prices, err := provider.Prices(context.Background())
+	if err != nil {
+		cli.Fatal("Error loading prices", err)
+	}
+
+	listed := 0
+	for _, upgrade := range shardData.NextUpgrades(levels, prices.Prices) {
+		s := shardData.Shards[upgrade.ID]
+		if *tag != "" && !s.EffectTags[*tag] {
+			continue
+		}
+		if listed == *top {
+			break
+		}
+		listed++
+		how := "buy"
+		if upgrade.Fused {
+			how = "fuse"
+		}
+		fmt.Printf("%-24s %2d -> %-2d %3d x %-4s %12.0f coins  +%-8g %s\n",
+			fmt.Sprintf("%s (%s)", s.Name, s.ID), upgrade.FromLevel, upgrade.ToLevel, upgrade.Shards, how,
+			upgrade.Cost, upgrade.EffectGain, strings.TrimSpace(s.RenderEffect(upgrade.ToLevel)))
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/apps/shardcalc"
+	"github.com/andu2/andu-skyblock-tools/data"
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+	"github.com/andu2/andu-skyblock-tools/pkg/server"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const usage = "usage: skyblock serve|sync [flags]"
+
+func main() {
+	if len(os.Args) < 2 {
+		cli.Fatal("Missing command", fmt.Errorf("%w: %s", cli.ErrUsage, usage))
+	}
+	switch os.Args[1] {
+	case "serve":
+		serve(os.Args[2:])
+	case "sync":
+		syncItems(os.Args[2:])
+	default:
+		cli.Fatal("Unknown command "+os.Args[1], fmt.Errorf("%w: %s", cli.ErrUsage, usage))
+	}
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to serve the app on")
+	in := flags.String("in", "", "Shard data to serve instead of the embedded copy")
+	refresh := flags.Duration("refresh", 5*time.Minute, "Time between price refreshes")
+	priceFlags := cli.AddPriceFlags(flags, "", true)
+	flags.Parse(args)
+
+	var shardData *shards.ProcessedShardData
+	var err error
+	if *in != "" {
+		shardData, err = shards.ProcessShards(*in)
+	} else {
+		shardData, err = shards.ProcessShardJSON(data.ShardsJSON, shards.ProcessOptions{})
+	}
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+
+	ui := shardcalc.UI()
+	if ui == nil {
+		log.Printf("Built without the web app; only the API is served")
+	}
+	srv, err := server.New(shardData, ui)
+	if err != nil {
+		cli.Fatal("Error preparing shard data", err)
+	}
+
+	if provider, err := priceFlags.Provider(shardData); err != nil {
+		log.Printf("%v; prices will not be served", err)
+	} else {
+		go srv.RefreshPrices(context.Background(), *refresh, provider)
+	}
+
+	log.Printf("Serving the shard calculator on %s", *addr)
+	cli.Fatal("Error serving", http.ListenAndServe(*addr, srv.Handler()))
+}
+
+// syncItems checks the shard data against the game's item list
+func syncItems(args []string) {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	in := flags.String("in", "data/shards.json", "Input shard data: a JSON, YAML or TOML file, or a directory of them")
+	stubs := flags.String("stubs", "", "Optional file to write stub entries for missing shards to, like data/new_shards.json")
+	asJSON := flags.Bool("json", false, "Print the report as JSON")
+	flags.Parse(args)
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	res, err := hypixel_api.GetItems(context.Background())
+	if err != nil {
+		cli.Fatal("Error getting items", err)
+	}
+	items := make([]shards.CatalogItem, 0)
+	for _, item := range res.ShardItems() {
+		items = append(items, shards.CatalogItem{ID: item.ID, Name: item.Name, Rarity: item.Rarity()})
+	}
+	report := shardData.CheckCatalog(items)
+
+	if *asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			cli.Fatal("Error formatting report", err)
+		}
+		fmt.Println(string(out))
+	} else {
+		printReport(report, len(items))
+	}
+
+	if *stubs != "" && len(report.Missing) > 0 {
+		ids, err := shardData.WriteShardStubs(report.Missing, *stubs)
+		if err != nil {
+			cli.Fatal("Error writing stubs", err)
+		}
+		log.Printf("Wrote stubs for %s to %s", strings.Join(ids, ", "), *stubs)
+	}
+}
+
+func printReport(report *shards.CatalogReport, items int) {
+	if report.IsEmpty() {
+		fmt.Printf("All %d shard items match the shard data\n", items)
+		return
+	}
+	for _, m := range report.Mismatches {
+		fmt.Printf("%s: %s is %q here but %q in game\n", m.ID, m.Field, m.Ours, m.Theirs)
+	}
+	for _, id := range report.Unlisted {
+		fmt.Printf("%s: not found in the item list\n", id)
+	}
+	for _, item := range report.Missing {
+		fmt.Printf("%s: missing from the shard data (%s, %s)\n", item.ID, item.Name, item.Rarity)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	priceFlags := cli.AddPriceFlags(flag.CommandLine, "data/shard_prices.json", false)
+	goalFlag := flag.String("goal", "", "Stats to reach, e.g. \"+50 strength, +30 health\"")
+	levelsFlag := flag.String("levels", "", "Current attribute levels as shard=level pairs, e.g. C1=3,Grove=10")
+	asJSON := flag.Bool("json", false, "Print the plan as JSON")
+	flag.Parse()
+
+	goals, err := shards.ParseStatGoals(*goalFlag)
+	if err != nil {
+		cli.Fatal("Error reading goal", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+	}
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	resolver, err := shards.NewResolver(shardData, nil)
+	if err != nil {
+		cli.Fatal("Error building shard resolver", err)
+	}
+	levels, err := shards.ParseLevels(*levelsFlag, resolver)
+	if err != nil {
+		cli.Fatal("Error reading levels", fmt.Errorf("%w: %w", cli.ErrUsage, err))
+	}
+	provider, err := priceFlags.Provider(shardData)
+	if err != nil {
+		cli.Fatal("Error choosing prices", err)
+	}
+	prices, err := provider.Prices(context.Background())
+	if err != nil {
+		cli.Fatal("Error loading prices", err)
+	}
+
+	plan, err := shardData.PlanStatGoals(goals, levels, prices.Prices)
+	if err != nil {
+		cli.Fatal("Error planning upgrades", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			cli.Fatal("Error writing plan", err)
+		}
+		return
+	}
+
+	if len(plan.Upgrades) == 0 {
+		fmt.Println("Current levels already meet every goal")
+	}
+	for _, upgrade := range plan.Upgrades {
+		s := shardData.Shards[upgrade.ID]
+		how := "buy"
+		if upgrade.Fused {
+			how = "fuse"
+		}
+		fmt.Printf("%-24s %2d -> %-2d %3d x %-4s %12.0f coins  %s\n",
+			fmt.Sprintf("%s (%s)", s.Name, s.ID), upgrade.FromLevel, upgrade.ToLevel, upgrade.Shards, how,
+			upgrade.Cost, strings.TrimSpace(s.RenderEffect(upgrade.ToLevel)))
+	}
+	fmt.Printf("Total: %.0f coins\n", plan.Cost)
+	for i, goal := range plan.Goals {
+		fmt.Printf("%s: reaches %g\n", goal, plan.Achieved[i])
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/internal/cli"
+	"github.com/andu2/andu-skyblock-tools/pkg/alerts"
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func main() {
+	in := flag.String("in", "data/shards.json", "Input file containing shard data")
+	rulesFile := flag.String("rules", "data/alerts.json", "Alert rules and sinks config")
+	interval := flag.Duration("interval", 5*time.Minute, "Time between price fetches")
+	record := flag.String("record", "", "Optional file to append every fetch to, like data/price_history.jsonl")
+	priceFlags := cli.AddPriceFlags(flag.CommandLine, "", true)
+	flag.Parse()
+
+	shardData, err := shards.ProcessShards(*in)
+	if err != nil {
+		cli.Fatal("Error processing shard data", err)
+	}
+	provider, err := priceFlags.Provider(shardData)
+	if err != nil {
+		cli.Fatal("Error choosing prices", err)
+	}
+	resolver, err := shards.NewResolver(shardData, nil)
+	if err != nil {
+		cli.Fatal("Error building shard resolver", err)
+	}
+	rules, sinks, err := alerts.LoadConfig(*rulesFile, resolver)
+	if err != nil {
+		cli.Fatal("Error loading alert rules", err)
+	}
+	engine := alerts.NewEngine(shardData, rules, sinks)
+
+	var history *prices.HistoryStore
+	if *record != "" {
+		history = &prices.HistoryStore{Path: *record}
+	}
+
+	for {
+		snap, err := provider.Prices(context.Background())
+		if err != nil {
+			log.Printf("Error getting prices: %v", err)
+		} else {
+			if history != nil {
+				if err := history.Append(snap); err != nil {
+					log.Printf("Error recording prices: %v", err)
+				}
+			}
+			if _, err := engine.Evaluate(alerts.Snapshot{Time: snap.Time, Prices: snap.Prices}); err != nil {
+				log.Printf("Error delivering alerts: %v", err)
+			}
+		}
+		time.Sleep(*interval)
+	}
+}
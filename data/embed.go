@@ -0,0 +1,7 @@
+// Package data embeds the default shard data so binaries can run outside the repo
+package data
+
+import _ "embed"
+
+//go:embed shards.json
+var ShardsJSON []byte
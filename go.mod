@@ -1,3 +1,11 @@
 module github.com/andu2/andu-skyblock-tools
 
 go 1.24
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// Exit codes shared by every command, so scripts can tell failures apart
+const (
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitInvalidData = 3
+	ExitFileMissing = 4
+	ExitAPIKey      = 5
+	ExitRateLimited = 6
+)
+
+// ErrUsage marks errors caused by bad flags or arguments
+var ErrUsage = errors.New("invalid usage")
+
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, hypixel_api.ErrAPIKeyMissing):
+		return ExitAPIKey
+	case errors.Is(err, hypixel_api.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, shards.ErrInvalidShardData),
+		errors.Is(err, shards.ErrInvalidRequirement),
+		errors.Is(err, shards.ErrUnknownShard),
+		errors.Is(err, shards.ErrAmbiguousShard):
+		return ExitInvalidData
+	case errors.Is(err, fs.ErrNotExist):
+		return ExitFileMissing
+	default:
+		return ExitError
+	}
+}
+
+// Fatal logs the error with some context and exits with the code matching it
+func Fatal(context string, err error) {
+	log.Printf("%s: %v", context, err)
+	os.Exit(ExitCode(err))
+}
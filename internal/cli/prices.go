@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// PriceFlags are the flags every command that needs prices takes to say where they come from
+type PriceFlags struct {
+	file           *string
+	live           *bool
+	auctions       *bool
+	auctionWorkers *int
+	override       *string
+	history        *string
+	at             *string
+}
+
+func AddPriceFlags(flags *flag.FlagSet, defaultFile string, defaultLive bool) *PriceFlags {
+	return &PriceFlags{
+		file:           flags.String("prices", defaultFile, "Prices file: a shard_prices.json, or a CSV or JSON of bazaar ID to price"),
+		live:           flags.Bool("live", defaultLive, "Fetch prices from the bazaar, falling back to -prices"),
+		auctions:       flags.Bool("auctions", false, "Price shards the bazaar doesn't have at their lowest auction house BIN"),
+		auctionWorkers: flags.Int("auction-workers", 8, "Auction pages to fetch at once"),
+		override:       flags.String("override", "", "Comma separated price files that take priority over every other source, for what-ifs"),
+		history:        flags.String("history", "data/price_history.jsonl", "Price history written by watch_prices -record"),
+		at:             flags.String("at", "", "Use prices from -history as of this RFC 3339 time instead of -prices, -live or -auctions"),
+	}
+}
+
+// Provider combines the sources the flags name, overrides first. Overrides are required, so a
+// broken one fails instead of going unnoticed, while a missing API key only drops the bazaar when
+// there is another source to fall back to. Auction prices are limited to shardData.
+func (f *PriceFlags) Provider(shardData *shards.ProcessedShardData) (prices.PriceProvider, error) {
+	var providers prices.Composite
+	for _, path := range strings.Split(*f.override, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			providers = append(providers, prices.Required{PriceProvider: prices.File{Path: path}})
+		}
+	}
+
+	if *f.at != "" {
+		at, err := time.Parse(time.RFC3339, *f.at)
+		if err != nil {
+			return nil, fmt.Errorf("%w: -at: %w", ErrUsage, err)
+		}
+		providers = append(providers, prices.History{Store: &prices.HistoryStore{Path: *f.history}, At: at})
+	} else {
+		if *f.live {
+			apiKey, err := hypixel_api.APIKeyFromEnv()
+			switch {
+			case err == nil:
+				providers = append(providers, prices.Bazaar{APIKey: apiKey})
+			case *f.file == "" && !*f.auctions:
+				return nil, err
+			default:
+				log.Printf("%v; bazaar prices will not be fetched", err)
+			}
+		}
+		if *f.auctions {
+			items := make([]string, 0, len(shardData.Shards))
+			for _, s := range shardData.Shards {
+				items = append(items, s.BazaarId)
+			}
+			providers = append(providers, prices.Auctions{Workers: *f.auctionWorkers, Items: items})
+		}
+		if *f.file != "" {
+			providers = append(providers, prices.File{Path: *f.file})
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("%w: no price source, set -prices, -live, -auctions or -at", ErrUsage)
+	case 1:
+		return providers[0], nil
+	}
+	return providers, nil
+}
@@ -0,0 +1,159 @@
+package hypixel_api
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+type Auction struct {
+	UUID        string  `json:"uuid"`
+	ItemName    string  `json:"item_name"`
+	Tier        string  `json:"tier"`
+	StartingBid float64 `json:"starting_bid"`
+	Bin         bool    `json:"bin"`
+	ItemBytes   string  `json:"item_bytes"`
+}
+
+type AuctionsResponse struct {
+	Success     bool      `json:"success"`
+	Page        int       `json:"page"`
+	TotalPages  int       `json:"totalPages"`
+	LastUpdated int64     `json:"lastUpdated"`
+	Auctions    []Auction `json:"auctions"`
+}
+
+type EndedAuction struct {
+	AuctionID string  `json:"auction_id"`
+	Timestamp int64   `json:"timestamp"`
+	Price     float64 `json:"price"`
+	Bin       bool    `json:"bin"`
+	ItemBytes string  `json:"item_bytes"`
+}
+
+type EndedAuctionsResponse struct {
+	Success     bool           `json:"success"`
+	LastUpdated int64          `json:"lastUpdated"`
+	Auctions    []EndedAuction `json:"auctions"`
+}
+
+// GetAuctionsPage fetches one page of active auctions. Like the other auction endpoints, it
+// doesn't need an API key.
+func GetAuctionsPage(ctx context.Context, page int) (*AuctionsResponse, error) {
+	res := AuctionsResponse{}
+	req := HypixelApiRequest{Endpoint: "/skyblock/auctions", Query: map[string]string{"page": strconv.Itoa(page)}}
+	if err := getJSON(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetAllAuctions fetches the first page to learn how many there are, then the rest with at most
+// workers requests at a time. Workers <= 0 means one per CPU. Any failed page fails the scan, since
+// a partial one would make missing listings look like higher prices. Cancelling ctx stops the scan
+// the same way.
+func GetAllAuctions(ctx context.Context, workers int) ([]Auction, error) {
+	first, err := GetAuctionsPage(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error getting auctions page 0: %w", err)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	pages := make([][]Auction, max(first.TotalPages, 1))
+	pages[0] = first.Auctions
+	next := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for range min(workers, len(pages)-1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range next {
+				res, err := GetAuctionsPage(ctx, page)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("error getting auctions page %d: %w", page, err)
+				}
+				mu.Unlock()
+				if err == nil {
+					pages[page] = res.Auctions
+				}
+			}
+		}()
+	}
+	for page := 1; page < len(pages); page++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		select {
+		case next <- page:
+		case <-ctx.Done():
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = ctx.Err()
+			}
+			mu.Unlock()
+		}
+	}
+	close(next)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	auctions := make([]Auction, 0, len(pages)*len(first.Auctions))
+	for _, page := range pages {
+		auctions = append(auctions, page...)
+	}
+	return auctions, nil
+}
+
+// GetEndedAuctions fetches the auctions that ended in the last minute
+func GetEndedAuctions(ctx context.Context) (*EndedAuctionsResponse, error) {
+	res := EndedAuctionsResponse{}
+	if err := getJSON(ctx, HypixelApiRequest{Endpoint: "/skyblock/auctions_ended"}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// LowestBINs returns the cheapest buy-it-now price per item, keyed by item ID and divided by the
+// stack size. Active listings come first; recent sales only price items nobody is listing. Auctions
+// of several different items are skipped, since their price can't be split between them.
+func LowestBINs(active []Auction, ended []EndedAuction) map[string]float64 {
+	lowest := make(map[string]float64)
+	addLowest(lowest, active, func(a Auction) (bool, float64, string) { return a.Bin, a.StartingBid, a.ItemBytes })
+	sold := make(map[string]float64)
+	addLowest(sold, ended, func(a EndedAuction) (bool, float64, string) { return a.Bin, a.Price, a.ItemBytes })
+	for id, price := range sold {
+		if _, listed := lowest[id]; !listed {
+			lowest[id] = price
+		}
+	}
+	return lowest
+}
+
+func addLowest[A any](lowest map[string]float64, auctions []A, fields func(A) (bool, float64, string)) {
+	for _, auction := range auctions {
+		bin, price, itemBytes := fields(auction)
+		if !bin || price <= 0 {
+			continue
+		}
+		items, err := DecodeItemBytes(itemBytes)
+		if err != nil || len(items) != 1 {
+			continue
+		}
+		perItem := price / float64(items[0].Count)
+		if current, ok := lowest[items[0].ID]; !ok || perItem < current {
+			lowest[items[0].ID] = perItem
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package hypixel_api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// itemBytes encodes a stack the way auctions do, with a display name and lore around the ID so
+// the decoder has to skip other tags
+func itemBytes(t *testing.T, id string, count int8) string {
+	t.Helper()
+	var nbt bytes.Buffer
+	name := func(s string) {
+		binary.Write(&nbt, binary.BigEndian, uint16(len(s)))
+		nbt.WriteString(s)
+	}
+	tag := func(tagType byte, s string) {
+		nbt.WriteByte(tagType)
+		name(s)
+	}
+	tag(nbtCompound, "")
+	tag(nbtList, "i")
+	nbt.WriteByte(nbtCompound)
+	binary.Write(&nbt, binary.BigEndian, int32(1))
+	tag(nbtShort, "id")
+	binary.Write(&nbt, binary.BigEndian, int16(409))
+	tag(nbtByte, "Count")
+	nbt.WriteByte(byte(count))
+	tag(nbtCompound, "tag")
+	tag(nbtCompound, "display")
+	tag(nbtString, "Name")
+	name("§9" + id)
+	tag(nbtList, "Lore")
+	nbt.WriteByte(nbtString)
+	binary.Write(&nbt, binary.BigEndian, int32(2))
+	name("§7A shard")
+	name("§7Fuse it")
+	nbt.WriteByte(nbtEnd)
+	tag(nbtCompound, "ExtraAttributes")
+	tag(nbtString, "id")
+	name(id)
+	tag(nbtLong, "timestamp")
+	binary.Write(&nbt, binary.BigEndian, int64(1700000000000))
+	nbt.WriteByte(nbtEnd)
+	nbt.WriteByte(nbtEnd)
+	nbt.WriteByte(nbtEnd)
+	nbt.WriteByte(nbtEnd)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(nbt.Bytes())
+	writer.Close()
+	return base64.StdEncoding.EncodeToString(compressed.Bytes())
+}
+
+// fakeAuctions serves pages of auctions and the ended auctions, failing failPage if it's set, and
+// records the most page requests it saw at once
+func fakeAuctions(t *testing.T, pages [][]Auction, ended []EndedAuction, failPage int) *int {
+	t.Helper()
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body any
+		switch req.URL.Path {
+		case "/skyblock/auctions":
+			page, err := strconv.Atoi(req.URL.Query().Get("page"))
+			if err != nil || page < 0 || page >= len(pages) {
+				http.NotFound(w, req)
+				return
+			}
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			// Long enough for concurrent requests to overlap
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			if page == failPage {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body = AuctionsResponse{Success: true, Page: page, TotalPages: len(pages), Auctions: pages[page]}
+		case "/skyblock/auctions_ended":
+			body = EndedAuctionsResponse{Success: true, Auctions: ended}
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		if req.Header.Get("API-Key") != "" {
+			t.Errorf("Auction endpoints should be called without an API key")
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	original := baseUrl
+	baseUrl = server.URL
+	t.Cleanup(func() { baseUrl = original })
+	return &maxInFlight
+}
+
+func TestAuctions(t *testing.T) {
+	griffin := itemBytes(t, "SHARD_GRIFFIN", 1)
+	pages := make([][]Auction, 6)
+	for i := range pages {
+		pages[i] = []Auction{
+			{UUID: "griffin" + strconv.Itoa(i), StartingBid: float64(1000 - i), Bin: true, ItemBytes: griffin},
+			{UUID: "zealot" + strconv.Itoa(i), StartingBid: 10, Bin: false, ItemBytes: itemBytes(t, "SHARD_ZEALOT", 1)},
+		}
+	}
+	pages[3] = append(pages[3], Auction{UUID: "stack", StartingBid: 640, Bin: true, ItemBytes: itemBytes(t, "SHARD_HIDEONLEAF", 64)})
+	ended := []EndedAuction{
+		{AuctionID: "sold1", Price: 900, Bin: true, ItemBytes: griffin},
+		{AuctionID: "sold2", Price: 50, Bin: true, ItemBytes: itemBytes(t, "SHARD_ZEALOT", 1)},
+		{AuctionID: "bad", Price: 1, Bin: true, ItemBytes: "not base64"},
+	}
+	maxInFlight := fakeAuctions(t, pages, ended, -1)
+
+	auctions, err := GetAllAuctions(t.Context(), 2)
+	if err != nil {
+		t.Fatalf("Failed to get auctions: %v", err)
+	}
+	if len(auctions) != 13 {
+		t.Errorf("Expected every auction on every page, got %d", len(auctions))
+	}
+	if *maxInFlight > 2 {
+		t.Errorf("Expected at most 2 pages at once, saw %d", *maxInFlight)
+	}
+
+	res, err := GetEndedAuctions(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get ended auctions: %v", err)
+	}
+	lowest := LowestBINs(auctions, res.Auctions)
+	want := map[string]float64{
+		// The cheapest listing beats a cheaper recent sale
+		"SHARD_GRIFFIN":    995,
+		"SHARD_HIDEONLEAF": 10,
+		// Only bid on, so it's priced by what it last sold for
+		"SHARD_ZEALOT": 50,
+	}
+	if len(lowest) != len(want) {
+		t.Errorf("Expected %v, got %v", want, lowest)
+	}
+	for id, price := range want {
+		if lowest[id] != price {
+			t.Errorf("%s: expected %g, got %g", id, price, lowest[id])
+		}
+	}
+}
+
+func TestAuctionsFailedPage(t *testing.T) {
+	pages := make([][]Auction, 10)
+	fakeAuctions(t, pages, nil, 4)
+	if _, err := GetAllAuctions(t.Context(), 3); err == nil {
+		t.Errorf("Expected a failed page to fail the scan")
+	}
+}
+
+func TestAuctionsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	var requested atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		requested.Add(1)
+		if page == 1 {
+			cancel()
+		}
+		json.NewEncoder(w).Encode(AuctionsResponse{Success: true, Page: page, TotalPages: 100})
+	}))
+	t.Cleanup(server.Close)
+	original := baseUrl
+	baseUrl = server.URL
+	t.Cleanup(func() { baseUrl = original })
+
+	if _, err := GetAllAuctions(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the scan to be cancelled, got %v", err)
+	}
+	if n := requested.Load(); n >= 10 {
+		t.Errorf("Expected the scan to stop once cancelled, but %d pages were requested", n)
+	}
+}
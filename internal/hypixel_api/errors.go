@@ -0,0 +1,50 @@
+package hypixel_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrAPIKeyMissing = errors.New("HYPIXEL_API_KEY environment variable is not set")
+	ErrRateLimited   = errors.New("rate limited by the Hypixel API")
+)
+
+// StatusError is returned for any non-200 response. It matches ErrRateLimited for 429s.
+type StatusError struct {
+	StatusCode int
+	// How long the API asked us to wait, if it said
+	RetryAfter time.Duration
+}
+
+func newStatusError(res *http.Response) *StatusError {
+	err := &StatusError{StatusCode: res.StatusCode}
+	if seconds, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return err
+}
+
+func (e *StatusError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return fmt.Sprintf("%v (status code %d)", ErrRateLimited, e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
+
+// APIKeyFromEnv reads the API key the commands use, failing with ErrAPIKeyMissing if it isn't set
+func APIKeyFromEnv() (string, error) {
+	apiKey := os.Getenv("HYPIXEL_API_KEY")
+	if apiKey == "" {
+		return "", ErrAPIKeyMissing
+	}
+	return apiKey, nil
+}
@@ -1,28 +1,24 @@
 package hypixel_api
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
-	"os"
-	"time"
 )
 
-const (
-	baseUrl = "https://api.hypixel.net/v2"
-)
+// A var so tests can point the client at a fake server
+var baseUrl = "https://api.hypixel.net/v2"
 
 type HypixelApiRequest struct {
 	Method   string
 	Endpoint string // Must have leading slash
-	ApiKey   string
+	ApiKey   string // Optional for resource endpoints
 	Query    map[string]string
 }
 
-func DoApiRequest(req HypixelApiRequest) (*http.Response, error) {
+func DoApiRequest(ctx context.Context, req HypixelApiRequest) (*http.Response, error) {
 	fullUrl, err := url.Parse(baseUrl + req.Endpoint)
 	if err != nil {
 		return nil, err
@@ -35,11 +31,13 @@ func DoApiRequest(req HypixelApiRequest) (*http.Response, error) {
 		fullUrl.RawQuery = query.Encode()
 	}
 
-	httpReq, err := http.NewRequest(req.Method, fullUrl.String(), nil)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, fullUrl.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("API-Key", req.ApiKey)
+	if req.ApiKey != "" {
+		httpReq.Header.Set("API-Key", req.ApiKey)
+	}
 
 	res, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
@@ -58,65 +56,48 @@ type BazaarResponse struct {
 	} `json:"products"`
 }
 
-func GetBazaar(apiKey string) (*BazaarResponse, error) {
-	res, err := DoApiRequest(HypixelApiRequest{
-		Method:   "GET",
-		Endpoint: "/skyblock/bazaar",
-		ApiKey:   apiKey,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if res.StatusCode != http.StatusOK {
-		return nil, &url.Error{
-			Op:  "GET",
-			URL: baseUrl + "/skyblock/bazaar",
-			Err: fmt.Errorf("unexpected status code: %d", res.StatusCode),
-		}
-	}
-
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+func GetBazaar(ctx context.Context, apiKey string) (*BazaarResponse, error) {
+	if apiKey == "" {
+		return nil, ErrAPIKeyMissing
 	}
 	bazaarResponse := BazaarResponse{}
-	err = json.Unmarshal(body, &bazaarResponse)
-	if err != nil {
+	if err := getJSON(ctx, HypixelApiRequest{Endpoint: "/skyblock/bazaar", ApiKey: apiKey}, &bazaarResponse); err != nil {
 		return nil, err
 	}
-
 	return &bazaarResponse, nil
 }
 
-type ShardBazaarOutput struct {
-	Timestamp   int64              `json:"timestamp"`
-	ShardPrices map[string]float64 `json:"shardPrices"`
-}
-
-func DumpShardPrices(apiKey string, outFile string) {
-	bazaar, err := GetBazaar(apiKey)
+// getJSON does a GET request and decodes the response into out
+func getJSON(ctx context.Context, req HypixelApiRequest, out any) error {
+	req.Method = "GET"
+	res, err := DoApiRequest(ctx, req)
 	if err != nil {
-		log.Fatalf("Error getting bazaar data: %v", err)
+		return err
 	}
 
-	shardBazaarOutput := ShardBazaarOutput{
-		Timestamp:   time.Now().Unix(),
-		ShardPrices: make(map[string]float64),
-	}
-	for item, data := range bazaar.Products {
-		if len(item) >= 6 && item[:6] == "SHARD_" {
-			shardBazaarOutput.ShardPrices[item] = data.QuickStatus.BuyPrice
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &url.Error{
+			Op:  "GET",
+			URL: baseUrl + req.Endpoint,
+			Err: newStatusError(res),
 		}
 	}
 
-	outJson, err := json.MarshalIndent(shardBazaarOutput, "", "  ")
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		log.Fatalf("Error formatting JSON: %v", err)
+		return err
 	}
-	if err := os.WriteFile(outFile, outJson, 0644); err != nil {
-		log.Fatalf("Error writing response to file: %v", err)
+	return json.Unmarshal(body, out)
+}
+
+// ShardPrices returns the instant-buy price of every shard product, keyed by bazaar ID
+func (b *BazaarResponse) ShardPrices() map[string]float64 {
+	prices := make(map[string]float64)
+	for item, data := range b.Products {
+		if len(item) >= 6 && item[:6] == "SHARD_" {
+			prices[item] = data.QuickStatus.BuyPrice
+		}
 	}
-	log.Printf("Response written to %s", outFile)
+	return prices
 }
@@ -0,0 +1,53 @@
+package hypixel_api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAPI serves testdata/items.json, a response of the items endpoint trimmed to a few items,
+// and rate limits everything else
+func fakeAPI(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/resources/skyblock/items" {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if req.Header.Get("API-Key") != "" {
+			t.Errorf("Resource endpoints should be called without an API key")
+		}
+		http.ServeFile(w, req, "testdata/items.json")
+	}))
+	t.Cleanup(server.Close)
+	original := baseUrl
+	baseUrl = server.URL
+	t.Cleanup(func() { baseUrl = original })
+}
+
+func TestGetItems(t *testing.T) {
+	fakeAPI(t)
+	res, err := GetItems(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get items: %v", err)
+	}
+	if !res.Success || len(res.Items) != 9 {
+		t.Fatalf("Unexpected items response: %+v", res)
+	}
+	shards := res.ShardItems()
+	if len(shards) != 7 {
+		t.Fatalf("Expected 7 shard items, got %d", len(shards))
+	}
+	if shards[0].ID != "SHARD_GROVE" || shards[0].Rarity() != "common" || shards[2].Rarity() != "uncommon" {
+		t.Errorf("Unexpected shard items: %+v", shards)
+	}
+
+	_, err = GetBazaar(t.Context(), "key")
+	var statusErr *StatusError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &statusErr) || statusErr.RetryAfter.Seconds() != 30 {
+		t.Errorf("Expected a rate limit error with Retry-After, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package hypixel_api
+
+import (
+	"context"
+	"strings"
+)
+
+type Item struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Material string `json:"material"`
+	// Uppercase rarity like "RARE". The API leaves it out for some common items.
+	Tier     string `json:"tier,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+type ItemsResponse struct {
+	Success     bool   `json:"success"`
+	LastUpdated int64  `json:"lastUpdated"`
+	Items       []Item `json:"items"`
+}
+
+// GetItems fetches every SkyBlock item. It's a resource endpoint, so no API key is needed.
+func GetItems(ctx context.Context) (*ItemsResponse, error) {
+	itemsResponse := ItemsResponse{}
+	if err := getJSON(ctx, HypixelApiRequest{Endpoint: "/resources/skyblock/items"}, &itemsResponse); err != nil {
+		return nil, err
+	}
+	return &itemsResponse, nil
+}
+
+// ShardItems returns the attribute shard items, whose IDs are the bazaar IDs of the shards
+func (r *ItemsResponse) ShardItems() []Item {
+	shards := make([]Item, 0)
+	for _, item := range r.Items {
+		if strings.HasPrefix(item.ID, "SHARD_") {
+			shards = append(shards, item)
+		}
+	}
+	return shards
+}
+
+// Rarity is the lowercase tier the shard data uses, with a missing tier counting as common
+func (i Item) Rarity() string {
+	if i.Tier == "" {
+		return "common"
+	}
+	return strings.ToLower(i.Tier)
+}
@@ -0,0 +1,215 @@
+package hypixel_api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Auctions only describe their item as item_bytes: base64 of gzipped NBT, Minecraft's binary
+// format. This reads just enough of it to get at the SkyBlock item ID.
+
+const (
+	nbtEnd = iota
+	nbtByte
+	nbtShort
+	nbtInt
+	nbtLong
+	nbtFloat
+	nbtDouble
+	nbtByteArray
+	nbtString
+	nbtList
+	nbtCompound
+	nbtIntArray
+	nbtLongArray
+)
+
+// ItemStack is one entry of item_bytes
+type ItemStack struct {
+	// The SkyBlock item ID, which is also the bazaar ID for bazaar items
+	ID    string
+	Count int
+}
+
+// DecodeItemBytes returns the items in an auction's item_bytes, usually exactly one
+func DecodeItemBytes(itemBytes string) ([]ItemStack, error) {
+	compressed, err := base64.StdEncoding.DecodeString(itemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item bytes: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid item bytes: %w", err)
+	}
+	r := &nbtReader{reader}
+	tagType, err := r.byte()
+	if err != nil {
+		return nil, fmt.Errorf("invalid item bytes: %w", err)
+	}
+	if tagType != nbtCompound {
+		return nil, fmt.Errorf("invalid item bytes: root is tag type %d, not a compound", tagType)
+	}
+	if _, err := r.string(); err != nil {
+		return nil, fmt.Errorf("invalid item bytes: %w", err)
+	}
+	root, err := r.payload(nbtCompound)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item bytes: %w", err)
+	}
+
+	list, _ := root.(map[string]any)["i"].([]any)
+	items := make([]ItemStack, 0, len(list))
+	for _, entry := range list {
+		item, _ := entry.(map[string]any)
+		tag, _ := item["tag"].(map[string]any)
+		extra, _ := tag["ExtraAttributes"].(map[string]any)
+		id, _ := extra["id"].(string)
+		if id == "" {
+			// Empty slots of a multi-item auction
+			continue
+		}
+		count, _ := item["Count"].(int8)
+		items = append(items, ItemStack{ID: id, Count: max(int(count), 1)})
+	}
+	return items, nil
+}
+
+type nbtReader struct {
+	r io.Reader
+}
+
+func (r *nbtReader) read(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r.r, buf)
+	return buf, err
+}
+
+func (r *nbtReader) byte() (byte, error) {
+	b, err := r.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *nbtReader) int32() (int32, error) {
+	b, err := r.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *nbtReader) string() (string, error) {
+	b, err := r.read(2)
+	if err != nil {
+		return "", err
+	}
+	s, err := r.read(int(binary.BigEndian.Uint16(b)))
+	return string(s), err
+}
+
+func (r *nbtReader) length() (int, error) {
+	n, err := r.int32()
+	if err != nil {
+		return 0, err
+	}
+	// Item data is a few kilobytes at most, so anything huge is corrupt
+	if n < 0 || n > 1<<20 {
+		return 0, fmt.Errorf("invalid length %d", n)
+	}
+	return int(n), nil
+}
+
+// payload reads a value of the given tag type. Compounds become maps and lists become slices;
+// numbers keep their width, so a byte is an int8.
+func (r *nbtReader) payload(tagType byte) (any, error) {
+	switch tagType {
+	case nbtByte:
+		b, err := r.byte()
+		return int8(b), err
+	case nbtShort:
+		b, err := r.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return int16(binary.BigEndian.Uint16(b)), nil
+	case nbtInt:
+		return r.int32()
+	case nbtLong:
+		b, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case nbtFloat:
+		n, err := r.int32()
+		return math.Float32frombits(uint32(n)), err
+	case nbtDouble:
+		b, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case nbtByteArray:
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		return r.read(n)
+	case nbtString:
+		return r.string()
+	case nbtList:
+		elemType, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, min(n, 1024))
+		for range n {
+			v, err := r.payload(elemType)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case nbtCompound:
+		compound := make(map[string]any)
+		for {
+			childType, err := r.byte()
+			if err != nil {
+				return nil, err
+			}
+			if childType == nbtEnd {
+				return compound, nil
+			}
+			name, err := r.string()
+			if err != nil {
+				return nil, err
+			}
+			if compound[name], err = r.payload(childType); err != nil {
+				return nil, err
+			}
+		}
+	case nbtIntArray, nbtLongArray:
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		width := 4
+		if tagType == nbtLongArray {
+			width = 8
+		}
+		return r.read(n * width)
+	}
+	return nil, fmt.Errorf("unknown tag type %d", tagType)
+}
@@ -0,0 +1,103 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// Snapshot is one bazaar fetch. Prices are keyed by bazaar ID.
+type Snapshot struct {
+	Time   time.Time
+	Prices map[string]float64
+}
+
+type Alert struct {
+	Rule    string         `json:"rule"`
+	Time    time.Time      `json:"time"`
+	Message string         `json:"message"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// EvalContext is everything a rule can look at. History holds previous snapshots, oldest first,
+// and never includes Current.
+type EvalContext struct {
+	Shards  *shards.ProcessedShardData
+	Current Snapshot
+	History []Snapshot
+}
+
+type Rule interface {
+	Name() string
+	Evaluate(ctx *EvalContext) []Alert
+}
+
+// Rules that compare against older prices report how much history they need
+type windowedRule interface {
+	Window() time.Duration
+}
+
+type Sink interface {
+	Send(alert Alert) error
+}
+
+type Engine struct {
+	Shards  *shards.ProcessedShardData
+	Rules   []Rule
+	Sinks   []Sink
+	history []Snapshot
+}
+
+func NewEngine(shardData *shards.ProcessedShardData, rules []Rule, sinks []Sink) *Engine {
+	return &Engine{
+		Shards: shardData,
+		Rules:  rules,
+		Sinks:  sinks,
+	}
+}
+
+// Evaluate runs every rule against the snapshot, sends the resulting alerts to every sink and
+// then records the snapshot for later comparisons. Sink errors do not stop other sinks.
+func (e *Engine) Evaluate(snap Snapshot) ([]Alert, error) {
+	ctx := &EvalContext{
+		Shards:  e.Shards,
+		Current: snap,
+		History: e.history,
+	}
+
+	fired := make([]Alert, 0)
+	for _, rule := range e.Rules {
+		fired = append(fired, rule.Evaluate(ctx)...)
+	}
+
+	var sinkErr error
+	for _, alert := range fired {
+		for _, sink := range e.Sinks {
+			if err := sink.Send(alert); err != nil && sinkErr == nil {
+				sinkErr = fmt.Errorf("error sending alert from rule %s: %w", alert.Rule, err)
+			}
+		}
+	}
+
+	e.history = append(e.history, snap)
+	e.trimHistory(snap.Time)
+
+	return fired, sinkErr
+}
+
+func (e *Engine) trimHistory(now time.Time) {
+	var keep time.Duration
+	for _, rule := range e.Rules {
+		if w, ok := rule.(windowedRule); ok && w.Window() > keep {
+			keep = w.Window()
+		}
+	}
+
+	cutoff := now.Add(-keep)
+	first := 0
+	for first < len(e.history)-1 && e.history[first].Time.Before(cutoff) {
+		first++
+	}
+	e.history = e.history[first:]
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const testShardDataLocation = "../../data/shards.json"
+
+func loadTestShards(t *testing.T) *shards.ProcessedShardData {
+	t.Helper()
+	shardData, err := shards.ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	return shardData
+}
+
+func flatPrices(shardData *shards.ProcessedShardData, price float64) map[string]float64 {
+	prices := make(map[string]float64, len(shardData.Shards))
+	for _, s := range shardData.Shards {
+		prices[s.BazaarId] = price
+	}
+	return prices
+}
+
+type recordingSink struct {
+	alerts []Alert
+}
+
+func (s *recordingSink) Send(alert Alert) error {
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func TestCheapestFusionBelow(t *testing.T) {
+	shardData := loadTestShards(t)
+	prices := flatPrices(shardData, 100)
+	paths := shardData.FusionPaths("R58", prices)
+	if len(paths) == 0 {
+		t.Fatal("Expected fusion paths for R58")
+	}
+
+	sink := &recordingSink{}
+	rule := &CheapestFusionBelow{Target: "R58", Threshold: paths[0].PricePerShard + 1}
+	engine := NewEngine(shardData, []Rule{rule}, []Sink{sink})
+
+	start := time.Unix(1700000000, 0)
+	for i := range 3 {
+		if _, err := engine.Evaluate(Snapshot{Time: start.Add(time.Duration(i) * time.Minute), Prices: prices}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(sink.alerts) != 1 {
+		t.Fatalf("Expected exactly 1 alert while price stays low, got %d", len(sink.alerts))
+	}
+
+	// Going back above the threshold re-arms the rule
+	engine.Evaluate(Snapshot{Time: start.Add(10 * time.Minute), Prices: flatPrices(shardData, 1000)})
+	engine.Evaluate(Snapshot{Time: start.Add(11 * time.Minute), Prices: prices})
+	if len(sink.alerts) != 2 {
+		t.Errorf("Expected rule to fire again after re-arming, got %d alerts", len(sink.alerts))
+	}
+}
+
+func TestPriceChange(t *testing.T) {
+	shardData := loadTestShards(t)
+	sink := &recordingSink{}
+	rule := &PriceChange{BazaarId: "SHARD_GROVE", Percent: 20, Duration: time.Hour}
+	engine := NewEngine(shardData, []Rule{rule}, []Sink{sink})
+
+	start := time.Unix(1700000000, 0)
+	steps := []struct {
+		offset time.Duration
+		price  float64
+	}{
+		{0, 100},
+		{30 * time.Minute, 110},
+		{50 * time.Minute, 115},
+		// More than an hour after the first snapshot, so 110 is now the baseline
+		{70 * time.Minute, 125},
+		{80 * time.Minute, 135},
+	}
+	for _, step := range steps {
+		snap := Snapshot{Time: start.Add(step.offset), Prices: map[string]float64{"SHARD_GROVE": step.price}}
+		if _, err := engine.Evaluate(snap); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(sink.alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(sink.alerts))
+	}
+	if got := sink.alerts[0].Data["previousPrice"]; got != 110.0 {
+		t.Errorf("Expected baseline price 110, got %v", got)
+	}
+}
+
+func TestNewProfitableFusion(t *testing.T) {
+	shardData := loadTestShards(t)
+	sink := &recordingSink{}
+	rule := &NewProfitableFusion{MinMargin: 0.1}
+	engine := NewEngine(shardData, []Rule{rule}, []Sink{sink})
+
+	prices := flatPrices(shardData, 100)
+	engine.Evaluate(Snapshot{Time: time.Unix(1700000000, 0), Prices: prices})
+	if len(sink.alerts) != 0 {
+		t.Fatalf("Expected first evaluation to only record a baseline, got %d alerts", len(sink.alerts))
+	}
+
+	target := shardData.Shards["R58"]
+	prices[target.BazaarId] = 1000000
+	engine.Evaluate(Snapshot{Time: time.Unix(1700000300, 0), Prices: prices})
+	found := false
+	for _, alert := range sink.alerts {
+		if path, ok := alert.Data["path"].(shards.FusionPath); ok && path.Target == "R58" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected an alert for R58 becoming profitable, got %v", sink.alerts)
+	}
+}
+
+func TestSinks(t *testing.T) {
+	alert := Alert{Rule: "test", Time: time.Unix(1700000000, 0).UTC(), Message: "hello"}
+
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- a
+	}))
+	defer server.Close()
+
+	if err := (&WebhookSink{URL: server.URL}).Send(alert); err != nil {
+		t.Fatalf("Webhook sink failed: %v", err)
+	}
+	if got := <-received; got.Message != alert.Message || !got.Time.Equal(alert.Time) {
+		t.Errorf("Webhook received %v, expected %v", got, alert)
+	}
+
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	sink := &JSONLSink{Path: path}
+	for range 2 {
+		if err := sink.Send(alert); err != nil {
+			t.Fatalf("JSONL sink failed: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read JSONL output: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("Expected 2 JSONL lines, got %d", len(lines))
+	}
+}
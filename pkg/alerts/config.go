@@ -0,0 +1,128 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+type alertConfig struct {
+	Rules []ruleConfig `json:"rules"`
+	Sinks []sinkConfig `json:"sinks"`
+}
+
+type ruleConfig struct {
+	Type      string  `json:"type"`
+	Target    string  `json:"target,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+	BazaarId  string  `json:"bazaarId,omitempty"`
+	Percent   float64 `json:"percent,omitempty"`
+	Window    string  `json:"window,omitempty"`
+	MinMargin float64 `json:"minMargin,omitempty"`
+}
+
+type sinkConfig struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// LoadConfig reads a JSON rules file. Targets and bazaar IDs may be any shard name the resolver
+// understands, e.g. "Grove" instead of "SHARD_GROVE". The file has the form
+//
+//	{
+//	  "rules": [
+//	    {"type": "cheapestFusionBelow", "target": "L33", "threshold": 50000},
+//	    {"type": "priceChange", "bazaarId": "SHARD_GROVE", "percent": 20, "window": "1h"},
+//	    {"type": "newProfitableFusion", "minMargin": 0.1}
+//	  ],
+//	  "sinks": [
+//	    {"type": "stdout"},
+//	    {"type": "jsonl", "path": "alerts.jsonl"},
+//	    {"type": "webhook", "url": "http://localhost:9000/alerts"}
+//	  ]
+//	}
+func LoadConfig(filePath string, resolver *shards.Resolver) ([]Rule, []Sink, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read alert config file: %w", err)
+	}
+
+	var config alertConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal alert config: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(config.Rules))
+	for i, rc := range config.Rules {
+		rule, err := buildRule(rc, resolver)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in rule %d: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	sinks := make([]Sink, 0, len(config.Sinks))
+	for i, sc := range config.Sinks {
+		sink, err := buildSink(sc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in sink %d: %w", i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return rules, sinks, nil
+}
+
+func buildRule(rc ruleConfig, resolver *shards.Resolver) (Rule, error) {
+	switch rc.Type {
+	case "cheapestFusionBelow":
+		if rc.Target == "" || rc.Threshold <= 0 {
+			return nil, fmt.Errorf("cheapestFusionBelow requires a target and a positive threshold")
+		}
+		target, err := resolver.ResolveOne(rc.Target)
+		if err != nil {
+			return nil, err
+		}
+		return &CheapestFusionBelow{Target: target.ID, Threshold: rc.Threshold}, nil
+	case "priceChange":
+		if rc.BazaarId == "" || rc.Percent <= 0 {
+			return nil, fmt.Errorf("priceChange requires a bazaarId and a positive percent")
+		}
+		s, err := resolver.ResolveOne(rc.BazaarId)
+		if err != nil {
+			return nil, err
+		}
+		window, err := time.ParseDuration(rc.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", rc.Window, err)
+		}
+		return &PriceChange{BazaarId: s.BazaarId, Percent: rc.Percent, Duration: window}, nil
+	case "newProfitableFusion":
+		return &NewProfitableFusion{MinMargin: rc.MinMargin}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type: %s", rc.Type)
+	}
+}
+
+func buildSink(sc sinkConfig) (Sink, error) {
+	switch sc.Type {
+	case "stdout":
+		return NewStdoutSink(), nil
+	case "jsonl":
+		if sc.Path == "" {
+			return nil, fmt.Errorf("jsonl sink requires a path")
+		}
+		return &JSONLSink{Path: sc.Path}, nil
+	case "webhook":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("webhook sink requires a url")
+		}
+		return &WebhookSink{URL: sc.URL}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type: %s", sc.Type)
+	}
+}
@@ -0,0 +1,166 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// CheapestFusionBelow fires when the cheapest fusion path for a shard drops below a threshold.
+// It fires once per crossing rather than on every fetch while the price stays low.
+type CheapestFusionBelow struct {
+	Target    string
+	Threshold float64
+	triggered bool
+}
+
+func (r *CheapestFusionBelow) Name() string {
+	return fmt.Sprintf("cheapestFusionBelow:%s", r.Target)
+}
+
+func (r *CheapestFusionBelow) Evaluate(ctx *EvalContext) []Alert {
+	paths := ctx.Shards.FusionPaths(r.Target, ctx.Current.Prices)
+	if len(paths) == 0 || paths[0].PricePerShard >= r.Threshold {
+		r.triggered = false
+		return nil
+	}
+	if r.triggered {
+		return nil
+	}
+	r.triggered = true
+
+	best := paths[0]
+	return []Alert{{
+		Rule: r.Name(),
+		Time: ctx.Current.Time,
+		Message: fmt.Sprintf("Cheapest fusion for %s is %.1f per shard (%dx %s + %dx %s), below %.1f",
+			r.Target, best.PricePerShard, best.Cost1, best.Shard1, best.Cost2, best.Shard2, r.Threshold),
+		Data: map[string]any{
+			"path":      best,
+			"threshold": r.Threshold,
+		},
+	}}
+}
+
+// PriceChange fires when a bazaar price moves by at least Percent compared to the oldest
+// snapshot still inside Window.
+type PriceChange struct {
+	BazaarId  string
+	Percent   float64
+	Duration  time.Duration
+	triggered bool
+}
+
+func (r *PriceChange) Name() string {
+	return fmt.Sprintf("priceChange:%s", r.BazaarId)
+}
+
+func (r *PriceChange) Window() time.Duration {
+	return r.Duration
+}
+
+func (r *PriceChange) Evaluate(ctx *EvalContext) []Alert {
+	current, ok := ctx.Current.Prices[r.BazaarId]
+	if !ok {
+		return nil
+	}
+
+	cutoff := ctx.Current.Time.Add(-r.Duration)
+	var baseline float64
+	var baselineTime time.Time
+	for _, snap := range ctx.History {
+		if snap.Time.Before(cutoff) {
+			continue
+		}
+		if price, ok := snap.Prices[r.BazaarId]; ok && price > 0 {
+			baseline = price
+			baselineTime = snap.Time
+			break
+		}
+	}
+	if baseline == 0 {
+		return nil
+	}
+
+	change := (current - baseline) / baseline * 100
+	if math.Abs(change) < r.Percent {
+		r.triggered = false
+		return nil
+	}
+	if r.triggered {
+		return nil
+	}
+	r.triggered = true
+
+	return []Alert{{
+		Rule: r.Name(),
+		Time: ctx.Current.Time,
+		Message: fmt.Sprintf("%s moved %+.1f%% (%.1f -> %.1f) since %s",
+			r.BazaarId, change, baseline, current, baselineTime.Format(time.Kitchen)),
+		Data: map[string]any{
+			"bazaarId":      r.BazaarId,
+			"previousPrice": baseline,
+			"currentPrice":  current,
+			"percentChange": change,
+		},
+	}}
+}
+
+// NewProfitableFusion fires for each shard whose cheapest fusion becomes cheaper than buying
+// it, by at least MinMargin (0.1 = 10%). The first evaluation only records a baseline.
+type NewProfitableFusion struct {
+	MinMargin  float64
+	profitable map[string]bool
+}
+
+func (r *NewProfitableFusion) Name() string {
+	return "newProfitableFusion"
+}
+
+func (r *NewProfitableFusion) Evaluate(ctx *EvalContext) []Alert {
+	nowProfitable := make(map[string]bool)
+	alerts := make([]Alert, 0)
+
+	cheapest := ctx.Shards.CheapestFusions(ctx.Current.Prices)
+	targets := make([]string, 0, len(cheapest))
+	for target := range cheapest {
+		targets = append(targets, target)
+	}
+	slices.Sort(targets)
+
+	for _, target := range targets {
+		shard, exists := ctx.Shards.Shards[target]
+		if !exists {
+			continue
+		}
+		buyPrice, ok := ctx.Current.Prices[shard.BazaarId]
+		if !ok || buyPrice <= 0 {
+			continue
+		}
+		path := cheapest[target]
+		margin := (buyPrice - path.PricePerShard) / buyPrice
+		if margin < r.MinMargin {
+			continue
+		}
+
+		nowProfitable[target] = true
+		if r.profitable == nil || r.profitable[target] {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Rule: r.Name(),
+			Time: ctx.Current.Time,
+			Message: fmt.Sprintf("Fusing %s (%dx %s + %dx %s) costs %.1f per shard vs %.1f on the bazaar",
+				shard.Name, path.Cost1, path.Shard1, path.Cost2, path.Shard2, path.PricePerShard, buyPrice),
+			Data: map[string]any{
+				"path":      path,
+				"buyPrice":  buyPrice,
+				"marginPct": margin * 100,
+			},
+		})
+	}
+
+	r.profitable = nowProfitable
+	return alerts
+}
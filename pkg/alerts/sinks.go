@@ -0,0 +1,74 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+type WriterSink struct {
+	Writer io.Writer
+}
+
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{Writer: os.Stdout}
+}
+
+func (s *WriterSink) Send(alert Alert) error {
+	_, err := fmt.Fprintf(s.Writer, "[%s] %s: %s\n", alert.Time.Format("2006-01-02 15:04:05"), alert.Rule, alert.Message)
+	return err
+}
+
+// JSONLSink appends one JSON object per alert to a file
+type JSONLSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (s *JSONLSink) Send(alert Alert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// WebhookSink POSTs each alert as JSON to a URL
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Send(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned status code %d", s.URL, res.StatusCode)
+	}
+	return nil
+}
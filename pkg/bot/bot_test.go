@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const testShardDataLocation = "../../data/shards.json"
+
+func newTestRouter(t *testing.T) *Router {
+	t.Helper()
+	shardData, err := shards.ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	quotes := make(prices.Static, len(shardData.Shards))
+	for _, s := range shardData.Shards {
+		quotes[s.BazaarId] = 100
+	}
+	resolver, err := shards.NewResolver(shardData, map[string]string{"chamo": "L4"})
+	if err != nil {
+		t.Fatalf("Failed to build resolver: %v", err)
+	}
+	return NewRouter(shardData, resolver, quotes)
+}
+
+// fakeDiscord plays the part of the platform: it signs interactions the way Discord does and
+// posts them to the endpoint under test
+type fakeDiscord struct {
+	t       *testing.T
+	url     string
+	private ed25519.PrivateKey
+}
+
+func (f *fakeDiscord) send(in any) (*http.Response, interactionResponse) {
+	f.t.Helper()
+	body, _ := json.Marshal(in)
+	req, _ := http.NewRequest(http.MethodPost, f.url, bytes.NewReader(body))
+	timestamp := "1700000000"
+	sig := ed25519.Sign(f.private, append([]byte(timestamp), body...))
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		f.t.Fatalf("Request failed: %v", err)
+	}
+	defer res.Body.Close()
+	var out interactionResponse
+	if res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			f.t.Fatalf("Invalid response body: %v", err)
+		}
+	}
+	return res, out
+}
+
+func TestRouterCommands(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		message  string
+		contains string
+	}{
+		{"!fuse R6 C9", "R15"},
+		{"!fuse shard_grove \"Fog Elemental\"", "Grove (C1) + Mist (C2)"},
+		{"!cheapest R58", "Cheapest fusions for"},
+		{"!shard Grove", "Nature Elemental"},
+		{"!shard nature elemental", "Grove (C1)"},
+		{"!SHARD SHARD_MIST", "Mist (C2)"},
+		{"!shard", "Usage"},
+		{"!shard zzzz", "no shard matches"},
+		{"!shard grvoe", "Grove (C1)"},
+		{"!shard chamo", "(L4)"},
+		{"!search \"sea creature\" category:water", "Sea Archer (C14)"},
+		{"!search color:red", "unknown field"},
+		{"!frobnicate", "Unknown command"},
+		{"!help", "!cheapest"},
+	}
+	for _, tt := range tests {
+		resp := router.Handle(tt.message)
+		if resp == nil {
+			t.Errorf("%s: expected a response", tt.message)
+			continue
+		}
+		if md := resp.Markdown(); !strings.Contains(md, tt.contains) {
+			t.Errorf("%s: expected response to contain %q, got:\n%s", tt.message, tt.contains, md)
+		}
+	}
+
+	if resp := router.Handle("!shard L4"); resp.Color != 0xffaa00 {
+		t.Errorf("Expected the legendary color from the shard data, got %#x", resp.Color)
+	}
+	if resp := router.Handle("just chatting"); resp != nil {
+		t.Errorf("Expected no response to ordinary chat, got %v", resp)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`fuse "Nature Elemental"  C9`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(args) != 3 || args[1] != "Nature Elemental" || args[2] != "C9" {
+		t.Errorf("Unexpected args: %q", args)
+	}
+	if _, err := splitArgs(`fuse "R6 C9`); err == nil {
+		t.Error("Expected error for unterminated quote")
+	}
+
+	line := commandLine(interactionData{Name: "fuse", Options: []interactionOption{{Value: `a "b" \c`}, {Value: "C9"}}})
+	if args, err := splitArgs(line); err != nil || len(args) != 3 || args[1] != `a "b" \c` {
+		t.Errorf("Expected %s to split back into its options, got %q (%v)", line, args, err)
+	}
+}
+
+func TestInteractionHandler(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	server := httptest.NewServer(&InteractionHandler{Router: newTestRouter(t), PublicKey: public})
+	defer server.Close()
+	discord := &fakeDiscord{t: t, url: server.URL, private: private}
+
+	res, out := discord.send(map[string]any{"type": interactionTypePing})
+	if res.StatusCode != http.StatusOK || out.Type != responseTypePong {
+		t.Errorf("Expected pong, got status %d type %d", res.StatusCode, out.Type)
+	}
+
+	res, out = discord.send(map[string]any{
+		"type": interactionTypeApplicationCommand,
+		"data": map[string]any{
+			"name": "fuse",
+			"options": []map[string]any{
+				{"name": "first", "value": "R6"},
+				{"name": "second", "value": "C9"},
+			},
+		},
+	})
+	if res.StatusCode != http.StatusOK || out.Type != responseTypeChannelMessage {
+		t.Fatalf("Expected channel message, got status %d type %d", res.StatusCode, out.Type)
+	}
+	if len(out.Data.Embeds) != 1 || !strings.Contains(out.Data.Embeds[0].Title, "(R6) + ") {
+		t.Errorf("Unexpected embeds: %+v", out.Data.Embeds)
+	}
+
+	// A key the endpoint doesn't trust must be rejected
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	impostor := &fakeDiscord{t: t, url: server.URL, private: otherKey}
+	if res, _ := impostor.send(map[string]any{"type": interactionTypePing}); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for bad signature, got %d", res.StatusCode)
+	}
+}
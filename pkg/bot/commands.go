@@ -0,0 +1,170 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const maxCheapestPaths = 5
+
+func handleFuse(r *Router, args []string) *Response {
+	s1, err := r.Resolver.ResolveOne(args[0])
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+	s2, err := r.Resolver.ResolveOne(args[1])
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+
+	title := fmt.Sprintf("%s + %s", shardLabel(s1), shardLabel(s2))
+	combo, exists := s1.FuseCombinations[s2.ID]
+	if !exists {
+		return &Response{Title: title, Description: "These shards cannot be fused together."}
+	}
+
+	prices := r.currentPrices()
+	lines := make([]string, 0, len(combo.Results))
+	for i, result := range combo.Results {
+		line := fmt.Sprintf("%d. %s (%s fusion)", i+1, shardLabel(r.Shards.Shards[result.ID]), result.Type)
+		if result.Multiplier > 1 {
+			line += fmt.Sprintf(" x%d", result.Multiplier)
+		}
+		lines = append(lines, line)
+	}
+
+	resp := &Response{
+		Title:       title,
+		Description: strings.Join(lines, "\n"),
+		Fields: []Field{
+			{Name: "Inputs", Value: fmt.Sprintf("%dx %s, %dx %s", combo.Cost1, s1.Name, combo.Cost2, s2.Name)},
+		},
+	}
+	p1, ok1 := prices[s1.BazaarId]
+	p2, ok2 := prices[s2.BazaarId]
+	if ok1 && ok2 {
+		resp.Fields = append(resp.Fields, Field{
+			Name:  "Input cost",
+			Value: formatCoins(p1*float64(combo.Cost1) + p2*float64(combo.Cost2)),
+		})
+	}
+	return resp
+}
+
+func handleCheapest(r *Router, args []string) *Response {
+	target, err := r.Resolver.ResolveOne(args[0])
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+	prices := r.currentPrices()
+	if prices == nil {
+		return errorResponse("Prices are not available right now.")
+	}
+
+	title := "Cheapest fusions for " + shardLabel(target)
+	paths := r.Shards.FusionPaths(target.ID, prices)
+	if len(paths) == 0 {
+		return &Response{Title: title, Description: "No priced fusion produces this shard.", Color: rarityColor(target)}
+	}
+
+	lines := make([]string, 0, maxCheapestPaths)
+	for i, path := range paths[:min(len(paths), maxCheapestPaths)] {
+		lines = append(lines, fmt.Sprintf("%d. %dx %s + %dx %s: **%s** per shard (%s)",
+			i+1,
+			path.Cost1, r.Shards.Shards[path.Shard1].Name,
+			path.Cost2, r.Shards.Shards[path.Shard2].Name,
+			formatCoins(path.PricePerShard), path.Type))
+	}
+	resp := &Response{
+		Title:       title,
+		Description: strings.Join(lines, "\n"),
+		Color:       rarityColor(target),
+	}
+	if price, ok := prices[target.BazaarId]; ok {
+		resp.Fields = append(resp.Fields, Field{Name: "Bazaar price", Value: formatCoins(price)})
+	}
+	return resp
+}
+
+func handleShard(r *Router, args []string) *Response {
+	s, err := r.Resolver.ResolveOne(args[0])
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+
+	effect := strings.ReplaceAll(s.EffectDescription, "{{effect}}", strconv.FormatFloat(s.EffectMax, 'f', -1, 64))
+	effect = strings.ReplaceAll(effect, "{{effect2}}", strconv.FormatFloat(s.Effect2Max, 'f', -1, 64))
+
+	sources := make([]string, 0, len(s.Sources))
+	for _, src := range s.Sources {
+		sources = append(sources, src.SourceDesc)
+	}
+
+	resp := &Response{
+		Title:       shardLabel(s),
+		Description: fmt.Sprintf("**%s**: %s (at max level)", s.AttributeName, effect),
+		Color:       rarityColor(s),
+		Fields: []Field{
+			{Name: "Rarity", Value: string(s.Rarity), Inline: true},
+			{Name: "Category", Value: string(s.Category), Inline: true},
+			{Name: "Skill", Value: s.Skill, Inline: true},
+			{Name: "Sources", Value: strings.Join(sources, ", ")},
+		},
+	}
+	if len(s.Families) > 0 {
+		families := make([]string, 0, len(s.Families))
+		for family := range s.Families {
+			families = append(families, family)
+		}
+		slices.Sort(families)
+		resp.Fields = append(resp.Fields, Field{Name: "Families", Value: strings.Join(families, ", ")})
+	}
+	if price, ok := r.currentPrices()[s.BazaarId]; ok {
+		resp.Fields = append(resp.Fields, Field{Name: "Bazaar price", Value: formatCoins(price), Inline: true})
+	}
+	return resp
+}
+
+func shardLabel(s *shards.Shard) string {
+	if s == nil {
+		return "Unknown shard"
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, s.ID)
+}
+
+func formatCoins(coins float64) string {
+	switch {
+	case coins >= 1_000_000:
+		return strconv.FormatFloat(coins/1_000_000, 'f', 2, 64) + "M"
+	case coins >= 1_000:
+		return strconv.FormatFloat(coins/1_000, 'f', 1, 64) + "k"
+	default:
+		return strconv.FormatFloat(coins, 'f', 1, 64)
+	}
+}
+
+const maxSearchResults = 10
+
+func handleSearch(r *Router, args []string) *Response {
+	results, err := r.Search.Search(args[0])
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+	title := fmt.Sprintf("Search: %s", args[0])
+	if len(results) == 0 {
+		return &Response{Title: title, Description: "No shards match."}
+	}
+
+	lines := make([]string, 0, maxSearchResults+1)
+	for i, result := range results[:min(len(results), maxSearchResults)] {
+		lines = append(lines, fmt.Sprintf("%d. %s, %s %s", i+1, shardLabel(result.Shard), result.Shard.Rarity, result.Shard.AttributeName))
+	}
+	if len(results) > maxSearchResults {
+		lines = append(lines, fmt.Sprintf("...and %d more", len(results)-maxSearchResults))
+	}
+	return &Response{Title: title, Description: strings.Join(lines, "\n")}
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Interaction and response types from the Discord interactions API
+const (
+	interactionTypePing               = 1
+	interactionTypeApplicationCommand = 2
+	responseTypePong                  = 1
+	responseTypeChannelMessage        = 4
+)
+
+const maxInteractionBody = 1 << 20
+
+type interaction struct {
+	Type int             `json:"type"`
+	Data interactionData `json:"data"`
+}
+
+type interactionData struct {
+	Name    string              `json:"name"`
+	Options []interactionOption `json:"options"`
+}
+
+type interactionOption struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+type interactionResponse struct {
+	Type int                      `json:"type"`
+	Data *interactionResponseData `json:"data,omitempty"`
+}
+
+type interactionResponseData struct {
+	Content string      `json:"content,omitempty"`
+	Embeds  []*Response `json:"embeds,omitempty"`
+}
+
+// InteractionHandler serves a Discord-style interactions endpoint. Slash commands are turned
+// back into "!name arg..." lines so they go through the same router as chat messages.
+// If PublicKey is nil, request signatures are not checked, which is only meant for local use.
+type InteractionHandler struct {
+	Router    *Router
+	PublicKey ed25519.PublicKey
+}
+
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxInteractionBody))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+	if h.PublicKey != nil && !verifySignature(h.PublicKey, req.Header, body) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var in interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, "invalid interaction", http.StatusBadRequest)
+		return
+	}
+
+	var out interactionResponse
+	switch in.Type {
+	case interactionTypePing:
+		out = interactionResponse{Type: responseTypePong}
+	case interactionTypeApplicationCommand:
+		resp := h.Router.Handle(commandLine(in.Data))
+		if resp == nil {
+			resp = errorResponse("Unknown command")
+		}
+		out = interactionResponse{
+			Type: responseTypeChannelMessage,
+			Data: &interactionResponseData{Embeds: []*Response{resp}},
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unsupported interaction type %d", in.Type), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func commandLine(data interactionData) string {
+	parts := []string{commandPrefix + data.Name}
+	for _, opt := range data.Options {
+		if commands[data.Name].raw {
+			parts = append(parts, fmt.Sprint(opt.Value))
+			continue
+		}
+		parts = append(parts, quoteArg(fmt.Sprint(opt.Value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteArg quotes a value so splitArgs reads it back as one argument, quotes and all
+func quoteArg(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func verifySignature(key ed25519.PublicKey, header http.Header, body []byte) bool {
+	sig, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	timestamp := header.Get("X-Signature-Timestamp")
+	if timestamp == "" {
+		return false
+	}
+	return ed25519.Verify(key, append([]byte(timestamp), body...), sig)
+}
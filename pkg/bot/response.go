@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// Response mirrors the shape of a Discord embed so adapters can pass it through,
+// while Markdown gives plain chat transports something readable.
+type Response struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	Color       int     `json:"color,omitempty"`
+}
+
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+func (r *Response) Markdown() string {
+	var sb strings.Builder
+	if r.Title != "" {
+		sb.WriteString("**" + r.Title + "**\n")
+	}
+	if r.Description != "" {
+		sb.WriteString(r.Description + "\n")
+	}
+	for _, f := range r.Fields {
+		sb.WriteString("__" + f.Name + "__\n" + f.Value + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func errorResponse(message string) *Response {
+	return &Response{
+		Title:       "Error",
+		Description: message,
+		Color:       colorError,
+	}
+}
+
+const colorError = 0xff5555
+
+// rarityColor is the embed color of a shard, its rarity's in-game color
+func rarityColor(s *shards.Shard) int {
+	color, err := strconv.ParseInt(strings.TrimPrefix(s.RarityColor(), "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+	return int(color)
+}
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/search"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const commandPrefix = "!"
+
+type commandHandler func(r *Router, args []string) *Response
+
+type command struct {
+	usage   string
+	minArgs int
+	// Raw commands get the rest of the line as typed, quotes included
+	raw     bool
+	handler commandHandler
+}
+
+var commands = map[string]command{
+	"fuse":     {usage: "!fuse <shard> <shard>", minArgs: 2, handler: handleFuse},
+	"cheapest": {usage: "!cheapest <shard>", minArgs: 1, handler: handleCheapest},
+	"shard":    {usage: "!shard <shard>", minArgs: 1, handler: handleShard},
+	"search":   {usage: "!search <query>", minArgs: 1, raw: true, handler: handleSearch},
+}
+
+// Router turns chat commands into responses. It knows nothing about the transport; adapters
+// hand it the raw message text and deliver whatever comes back.
+type Router struct {
+	Shards   *shards.ProcessedShardData
+	Resolver *shards.Resolver
+	Search   *search.Index
+	// Prices provides current shard prices. It may be nil, in which case price-dependent
+	// commands say so instead of failing.
+	Prices prices.PriceProvider
+}
+
+func NewRouter(shardData *shards.ProcessedShardData, resolver *shards.Resolver, provider prices.PriceProvider) *Router {
+	return &Router{
+		Shards:   shardData,
+		Resolver: resolver,
+		Search:   search.NewIndex(shardData),
+		Prices:   provider,
+	}
+}
+
+// Handle returns nil when the message is not a command, so bots can ignore ordinary chat
+func (r *Router) Handle(message string) *Response {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, commandPrefix) {
+		return nil
+	}
+	args, err := splitArgs(strings.TrimPrefix(message, commandPrefix))
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	name := strings.ToLower(args[0])
+	if name == "help" {
+		return helpResponse()
+	}
+	cmd, exists := commands[name]
+	if !exists {
+		return errorResponse(fmt.Sprintf("Unknown command %q. Try !help", name))
+	}
+	args = args[1:]
+	if len(args) < cmd.minArgs {
+		return errorResponse("Usage: " + cmd.usage)
+	}
+	if cmd.raw {
+		line := strings.TrimPrefix(message, commandPrefix)
+		args = []string{strings.TrimSpace(line[strings.IndexFunc(line, unicode.IsSpace):])}
+	}
+	// Single-shard commands take the rest of the line, so "!shard Nature Elemental" works unquoted
+	if cmd.minArgs == 1 && !cmd.raw {
+		args = []string{strings.Join(args, " ")}
+	}
+	return cmd.handler(r, args)
+}
+
+func (r *Router) currentPrices() map[string]float64 {
+	if r.Prices == nil {
+		return nil
+	}
+	snap, err := r.Prices.Prices(context.Background())
+	if err != nil {
+		return nil
+	}
+	return snap.Prices
+}
+
+// splitArgs splits on whitespace, keeping double-quoted sections together. Inside quotes a
+// backslash escapes the next character, so quoted values can hold quotes.
+func splitArgs(line string) ([]string, error) {
+	args := make([]string, 0, 3)
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+	hasToken := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case unicode.IsSpace(c) && !inQuotes:
+			if hasToken {
+				args = append(args, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(c)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if hasToken {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func helpResponse() *Response {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	lines := make([]string, 0, len(names)+1)
+	lines = append(lines, "`!help`")
+	for _, name := range names {
+		lines = append(lines, "`"+commands[name].usage+"`")
+	}
+	return &Response{
+		Title:       "Commands",
+		Description: strings.Join(lines, "\n") + "\nShards can be given by ID, name, attribute name or bazaar ID.",
+	}
+}
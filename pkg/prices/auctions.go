@@ -0,0 +1,43 @@
+package prices
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+)
+
+// Auctions prices items at their lowest buy-it-now on the auction house, for the shards and
+// fusion outputs that never reach the bazaar. Put it after Bazaar in a Composite so it only fills
+// the gaps. A full scan takes a few seconds.
+type Auctions struct {
+	// Pages fetched at once, <= 0 for one per CPU
+	Workers int
+	// Item IDs to price, usually the shards' bazaar IDs. Empty prices everything listed.
+	Items []string
+}
+
+func (a Auctions) Prices(ctx context.Context) (*Snapshot, error) {
+	active, err := hypixel_api.GetAllAuctions(ctx, a.Workers)
+	if err != nil {
+		return nil, fmt.Errorf("error getting auctions: %w", err)
+	}
+	// Recent sales only help with items nobody is listing right now, so the scan stands without them
+	var sold []hypixel_api.EndedAuction
+	if ended, err := hypixel_api.GetEndedAuctions(ctx); err == nil {
+		sold = ended.Auctions
+	}
+
+	lowest := hypixel_api.LowestBINs(active, sold)
+	if len(a.Items) == 0 {
+		return &Snapshot{Time: time.Now(), Prices: lowest, Source: SourceAuctions}, nil
+	}
+	prices := make(map[string]float64, len(a.Items))
+	for _, id := range a.Items {
+		if price, ok := lowest[id]; ok {
+			prices[id] = price
+		}
+	}
+	return &Snapshot{Time: time.Now(), Prices: prices, Source: SourceAuctions}, nil
+}
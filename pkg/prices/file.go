@@ -0,0 +1,80 @@
+package prices
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File reads prices from disk. A .csv file has one bazaar ID and price per row, with an optional
+// header. A .json file is either shard_prices.json as get_shard_prices writes it or a plain object
+// of bazaar ID to price. The file is read on every call, so edits apply without a restart.
+type File struct {
+	Path string
+}
+
+func (f File) Prices(ctx context.Context) (*Snapshot, error) {
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prices: %w", err)
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prices: %w", err)
+	}
+	snap := &Snapshot{Time: info.ModTime(), Source: SourceFile}
+
+	switch strings.ToLower(filepath.Ext(f.Path)) {
+	case ".csv":
+		snap.Prices, err = parseCSV(content)
+	case ".json":
+		var sheet struct {
+			Timestamp   int64              `json:"timestamp"`
+			ShardPrices map[string]float64 `json:"shardPrices"`
+		}
+		if err = json.Unmarshal(content, &sheet); err == nil && sheet.ShardPrices != nil {
+			snap.Prices = sheet.ShardPrices
+			if sheet.Timestamp > 0 {
+				snap.Time = time.Unix(sheet.Timestamp, 0)
+			}
+		} else {
+			err = json.Unmarshal(content, &snap.Prices)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported price file %s, expected .csv or .json", f.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f.Path, err)
+	}
+	return snap, nil
+}
+
+func parseCSV(content []byte) (map[string]float64, error) {
+	reader := csv.NewReader(strings.NewReader(string(content)))
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[string]float64, len(rows))
+	for i, row := range rows {
+		price, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if err != nil {
+			if i == 0 {
+				// A header like "bazaarId,price"
+				continue
+			}
+			return nil, fmt.Errorf("row %d: invalid price %q", i+1, row[1])
+		}
+		prices[strings.TrimSpace(row[0])] = price
+	}
+	return prices, nil
+}
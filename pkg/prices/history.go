@@ -0,0 +1,85 @@
+package prices
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// HistoryStore keeps every snapshot it's given in a file, one JSON object per line, so prices
+// can be looked up as they were at any earlier time
+type HistoryStore struct {
+	Path string
+}
+
+func (h *HistoryStore) Append(snap *Snapshot) error {
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("error formatting snapshot: %w", err)
+	}
+	file, err := os.OpenFile(h.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening price history: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing price history: %w", err)
+	}
+	return file.Close()
+}
+
+// At returns the latest snapshot taken at or before t
+func (h *HistoryStore) At(t time.Time) (*Snapshot, error) {
+	file, err := os.Open(h.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no price history at %s", ErrNoPrices, h.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening price history: %w", err)
+	}
+	defer file.Close()
+
+	var found *Snapshot
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return nil, fmt.Errorf("error reading price history line %d: %w", line, err)
+		}
+		// Snapshots are appended as they're taken, but don't count on it
+		if !snap.Time.After(t) && (found == nil || snap.Time.After(found.Time)) {
+			found = &snap
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading price history: %w", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: price history starts after %s", ErrNoPrices, t.Format(time.RFC3339))
+	}
+	return found, nil
+}
+
+// History provides prices as the store had them at a fixed time. Snapshots recorded before
+// sources were kept are sourced to the history itself.
+type History struct {
+	Store *HistoryStore
+	At    time.Time
+}
+
+func (h History) Prices(ctx context.Context) (*Snapshot, error) {
+	snap, err := h.Store.At(h.At)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Source == "" && snap.Sources == nil {
+		snap.Source = SourceHistory
+	}
+	return snap, nil
+}
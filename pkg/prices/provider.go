@@ -0,0 +1,113 @@
+// Package prices gets shard prices from wherever they're needed from: the live bazaar, a history
+// of earlier fetches, a hand-written file, or several of these at once.
+package prices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/internal/hypixel_api"
+)
+
+var ErrNoPrices = errors.New("no prices available")
+
+// Where prices come from, so a quote from the auction house can be told apart from the bazaar's
+const (
+	SourceBazaar   = "bazaar"
+	SourceAuctions = "auctions"
+	SourceFile     = "file"
+	SourceStatic   = "static"
+	SourceHistory  = "history"
+)
+
+// Snapshot is a set of prices as of Time, keyed by bazaar ID. Every price came from Source unless
+// Sources, which Composite fills in, says otherwise.
+type Snapshot struct {
+	Time    time.Time          `json:"time"`
+	Prices  map[string]float64 `json:"prices"`
+	Source  string             `json:"source,omitempty"`
+	Sources map[string]string  `json:"sources,omitempty"`
+}
+
+// SourceOf returns where the price of id came from
+func (s *Snapshot) SourceOf(id string) string {
+	if source, ok := s.Sources[id]; ok {
+		return source
+	}
+	return s.Source
+}
+
+type PriceProvider interface {
+	Prices(ctx context.Context) (*Snapshot, error)
+}
+
+// ProviderFunc lets an ordinary function be a PriceProvider
+type ProviderFunc func(ctx context.Context) (*Snapshot, error)
+
+func (f ProviderFunc) Prices(ctx context.Context) (*Snapshot, error) {
+	return f(ctx)
+}
+
+// Bazaar fetches instant-buy prices from the Hypixel bazaar
+type Bazaar struct {
+	APIKey string
+}
+
+func (b Bazaar) Prices(ctx context.Context) (*Snapshot, error) {
+	bazaar, err := hypixel_api.GetBazaar(ctx, b.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bazaar data: %w", err)
+	}
+	return &Snapshot{Time: time.Now(), Prices: bazaar.ShardPrices(), Source: SourceBazaar}, nil
+}
+
+// Static always returns the same prices, which is handy for tests and quick what-ifs
+type Static map[string]float64
+
+func (s Static) Prices(ctx context.Context) (*Snapshot, error) {
+	return &Snapshot{Prices: maps.Clone(s), Source: SourceStatic}, nil
+}
+
+// Required marks a provider Composite can't do without, like an override file the user asked for
+type Required struct {
+	PriceProvider
+}
+
+// Composite combines providers in priority order: each price comes from the first provider that
+// has it, so an override file goes first and the bazaar after it. A provider that fails is skipped,
+// which makes later providers fallbacks; Composite only fails when all of them do, or when a
+// Required one does. The snapshot has the latest time of the providers that answered.
+type Composite []PriceProvider
+
+func (c Composite) Prices(ctx context.Context) (*Snapshot, error) {
+	merged := &Snapshot{Prices: make(map[string]float64), Sources: make(map[string]string)}
+	answered := false
+	var errs []error
+	for _, provider := range c {
+		snap, err := provider.Prices(ctx)
+		if err != nil {
+			if _, ok := provider.(Required); ok {
+				return nil, err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		answered = true
+		if snap.Time.After(merged.Time) {
+			merged.Time = snap.Time
+		}
+		for id, price := range snap.Prices {
+			if _, exists := merged.Prices[id]; !exists {
+				merged.Prices[id] = price
+				merged.Sources[id] = snap.SourceOf(id)
+			}
+		}
+	}
+	if !answered {
+		return nil, errors.Join(append([]error{ErrNoPrices}, errs...)...)
+	}
+	return merged, nil
+}
@@ -0,0 +1,120 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]float64
+		time    int64
+	}{
+		{"prices.csv", "bazaarId,price\nSHARD_GRIFFIN, 1000\n# what if\nSHARD_ZEALOT,2.5\n", map[string]float64{"SHARD_GRIFFIN": 1000, "SHARD_ZEALOT": 2.5}, 0},
+		{"prices.json", `{"SHARD_GRIFFIN": 1000}`, map[string]float64{"SHARD_GRIFFIN": 1000}, 0},
+		{"shard_prices.json", `{"timestamp": 100, "shardPrices": {"SHARD_GRIFFIN": 1000}, "prices": {}}`, map[string]float64{"SHARD_GRIFFIN": 1000}, 100},
+	}
+	for _, tt := range tests {
+		snap, err := File{Path: writeFile(t, tt.name, tt.content)}.Prices(t.Context())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(snap.Prices, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, snap.Prices)
+		}
+		if tt.time != 0 && snap.Time.Unix() != tt.time {
+			t.Errorf("%s: expected the file's timestamp, got %v", tt.name, snap.Time)
+		}
+	}
+
+	for _, bad := range []struct{ name, content string }{
+		{"prices.csv", "SHARD_GRIFFIN,1000\nSHARD_ZEALOT,cheap\n"},
+		{"prices.txt", "SHARD_GRIFFIN 1000"},
+	} {
+		if _, err := (File{Path: writeFile(t, bad.name, bad.content)}).Prices(t.Context()); err == nil {
+			t.Errorf("Expected %s with %q to be rejected", bad.name, bad.content)
+		}
+	}
+}
+
+func TestComposite(t *testing.T) {
+	down := ProviderFunc(func(ctx context.Context) (*Snapshot, error) {
+		return nil, errors.New("bazaar is down")
+	})
+	override := Static{"SHARD_GRIFFIN": 1}
+	fallback := ProviderFunc(func(ctx context.Context) (*Snapshot, error) {
+		return &Snapshot{Time: time.Unix(100, 0), Prices: map[string]float64{"SHARD_GRIFFIN": 1000, "SHARD_ZEALOT": 5}, Source: SourceBazaar}, nil
+	})
+
+	snap, err := Composite{override, down, fallback}.Prices(t.Context())
+	if err != nil {
+		t.Fatalf("A failing provider should be skipped: %v", err)
+	}
+	want := map[string]float64{"SHARD_GRIFFIN": 1, "SHARD_ZEALOT": 5}
+	if !reflect.DeepEqual(snap.Prices, want) || snap.Time.Unix() != 100 {
+		t.Errorf("Expected %v at 100, got %v at %v", want, snap.Prices, snap.Time.Unix())
+	}
+	if snap.SourceOf("SHARD_GRIFFIN") != SourceStatic || snap.SourceOf("SHARD_ZEALOT") != SourceBazaar {
+		t.Errorf("Expected each price to keep its provider's source, got %v", snap.Sources)
+	}
+	if override["SHARD_ZEALOT"] != 0 {
+		t.Errorf("Composite should not change the prices it's given")
+	}
+
+	if _, err := (Composite{down, down}).Prices(t.Context()); !errors.Is(err, ErrNoPrices) {
+		t.Errorf("Expected ErrNoPrices when every provider fails, got %v", err)
+	}
+
+	missing := Required{File{Path: filepath.Join(t.TempDir(), "missing.csv")}}
+	if _, err := (Composite{missing, fallback}).Prices(t.Context()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing override file to fail, got %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	store := &HistoryStore{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	if _, err := store.At(time.Now()); !errors.Is(err, ErrNoPrices) {
+		t.Errorf("Expected ErrNoPrices without history, got %v", err)
+	}
+	for _, at := range []int64{100, 300, 200} {
+		snap := &Snapshot{Time: time.Unix(at, 0), Prices: map[string]float64{"SHARD_GRIFFIN": float64(at)}}
+		if err := store.Append(snap); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+
+	tests := []struct {
+		at   int64
+		want float64
+	}{
+		{100, 100},
+		{250, 200},
+		{1000, 300},
+	}
+	for _, tt := range tests {
+		snap, err := History{Store: store, At: time.Unix(tt.at, 0)}.Prices(t.Context())
+		if err != nil || snap.Prices["SHARD_GRIFFIN"] != tt.want {
+			t.Errorf("At %d: expected %g, got %v (%v)", tt.at, tt.want, snap, err)
+		}
+	}
+	if _, err := store.At(time.Unix(50, 0)); !errors.Is(err, ErrNoPrices) {
+		t.Errorf("Expected ErrNoPrices before the first snapshot, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// Text fields searched by free text terms, and how much a match in each is worth
+var textFields = []struct {
+	name   string
+	weight float64
+}{
+	{"name", 10},
+	{"alias", 8},
+	{"attribute", 6},
+	{"effect", 3},
+	{"source", 2},
+}
+
+// Index answers queries over processed shard data. It never changes after being built, so one
+// index can be shared by the CLI, a server and a bot.
+type Index struct {
+	data *shards.ProcessedShardData
+	docs []document
+}
+
+type document struct {
+	shard *shards.Shard
+	order int
+	// Words of each text field, by field name. A field with several values, like sources,
+	// has one entry per value so phrases don't run across them.
+	text map[string][][]string
+}
+
+type Result struct {
+	Shard *shards.Shard
+	Score float64
+	// Text fields the terms matched in, best first
+	Matched []string
+}
+
+func NewIndex(data *shards.ProcessedShardData) *Index {
+	idx := &Index{data: data, docs: make([]document, 0, len(data.Shards))}
+	for _, s := range data.Shards {
+		doc := document{
+			shard: s,
+			order: data.RarityRank(string(s.Rarity))*1000 + s.Number,
+			text:  make(map[string][][]string, len(textFields)),
+		}
+		doc.add("name", s.Name)
+		for _, alias := range s.Aliases {
+			doc.add("alias", alias)
+		}
+		doc.add("attribute", s.AttributeName)
+		doc.add("effect", s.RenderEffect(shards.MaxAttributeLevel))
+		for _, stat := range s.Stats {
+			doc.add("effect", stat.Stat)
+		}
+		for _, src := range s.Sources {
+			doc.add("source", src.SourceDesc)
+		}
+		idx.docs = append(idx.docs, doc)
+	}
+	slices.SortFunc(idx.docs, func(a, b document) int {
+		return cmp.Or(cmp.Compare(a.order, b.order), cmp.Compare(a.shard.ID, b.shard.ID))
+	})
+	return idx
+}
+
+func (d *document) add(field string, text string) {
+	if words := tokenize(text); len(words) > 0 {
+		d.text[field] = append(d.text[field], words)
+	}
+}
+
+// Search parses the query and returns matching shards, best first. Without any free text terms
+// every match scores the same and results come in shard order.
+func (idx *Index) Search(query string) ([]Result, error) {
+	q, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Run(q)
+}
+
+// Run searches with a query that was already parsed. Rarities depend on the shard data, so they
+// are only checked here.
+func (idx *Index) Run(q *Query) ([]Result, error) {
+	for _, f := range q.Filters {
+		for _, value := range f.Values {
+			if f.Field == "rarity" && idx.data.RarityRank(value) == 0 {
+				return nil, fmt.Errorf("%w: unknown rarity %q", ErrInvalidQuery, value)
+			}
+		}
+	}
+
+	results := make([]Result, 0)
+	for i := range idx.docs {
+		doc := &idx.docs[i]
+		if !slices.ContainsFunc(q.Filters, func(f Filter) bool { return !idx.matches(f, doc.shard) }) {
+			if result, ok := doc.score(q.Terms); ok {
+				results = append(results, result)
+			}
+		}
+	}
+	slices.SortStableFunc(results, func(a, b Result) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return results, nil
+}
+
+func (d *document) score(terms []Term) (Result, bool) {
+	result := Result{Shard: d.shard}
+	fieldScores := make(map[string]float64)
+	for _, term := range terms {
+		best, bestField := 0.0, ""
+		for _, field := range textFields {
+			for _, words := range d.text[field.name] {
+				if s := matchWords(words, term.Words) * field.weight; s > best {
+					best, bestField = s, field.name
+				}
+			}
+		}
+		if term.Negate != (best == 0) {
+			return Result{}, false
+		}
+		if !term.Negate {
+			result.Score += best
+			fieldScores[bestField] = max(fieldScores[bestField], best)
+		}
+	}
+	for field := range fieldScores {
+		result.Matched = append(result.Matched, field)
+	}
+	slices.SortFunc(result.Matched, func(a, b string) int {
+		return cmp.Or(cmp.Compare(fieldScores[b], fieldScores[a]), cmp.Compare(a, b))
+	})
+	return result, true
+}
+
+// matchWords scores how well the term words appear in order within the field words: 2 when they
+// are the whole field, 1 when they appear exactly, and 0.5 when the last word is only a prefix
+// of a field word, for terms typed as you go. A term with no words matches nothing.
+func matchWords(field []string, term []string) float64 {
+	if len(term) == 0 {
+		return 0
+	}
+	if slices.Equal(field, term) {
+		return 2
+	}
+	best := 0.0
+	last := len(term) - 1
+	for start := 0; start+len(term) <= len(field); start++ {
+		if !slices.Equal(field[start:start+last], term[:last]) {
+			continue
+		}
+		switch word := field[start+last]; {
+		case word == term[last]:
+			return 1
+		case strings.HasPrefix(word, term[last]):
+			best = 0.5
+		}
+	}
+	return best
+}
+
+func (idx *Index) matches(f Filter, s *shards.Shard) bool {
+	matched := false
+	for _, value := range f.Values {
+		if idx.matchesValue(f, s, value) {
+			matched = true
+			break
+		}
+	}
+	return matched != f.Negate
+}
+
+func (idx *Index) matchesValue(f Filter, s *shards.Shard, value string) bool {
+	switch f.Field {
+	case "id":
+		return strings.EqualFold(s.ID, value)
+	case "skill":
+		return strings.EqualFold(s.Skill, value)
+	case "category":
+		return strings.EqualFold(string(s.Category), value)
+	case "rarity":
+		rank, want := idx.data.RarityRank(string(s.Rarity)), idx.data.RarityRank(value)
+		switch f.Op {
+		case ">=":
+			return rank >= want
+		case "<=":
+			return rank <= want
+		case ">":
+			return rank > want
+		case "<":
+			return rank < want
+		}
+		return rank == want
+	case "family":
+		return s.Families[value]
+	case "tag":
+		return s.EffectTags[value]
+	case "source":
+		// Either a source type like "net" or words from the source, like "hunt cod"
+		for _, src := range s.Sources {
+			if strings.EqualFold(src.SourceType, value) || matchWords(tokenize(src.SourceDesc), tokenize(value)) >= 1 {
+				return true
+			}
+		}
+	case "stat":
+		for _, stat := range s.Stats {
+			if stat.Stat == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// Query is a parsed search. Every filter and every term must match for a shard to be found.
+type Query struct {
+	Filters []Filter
+	Terms   []Term
+}
+
+// Filter narrows results by a shard property, like "skill:fishing" or "rarity>=rare". Values
+// separated by commas match any of them.
+type Filter struct {
+	Field  string
+	Op     string
+	Values []string
+	Negate bool
+}
+
+// Term is free text matched against names, attributes, effects and sources. A quoted term is a
+// phrase whose words must appear together.
+type Term struct {
+	Words  []string
+	Negate bool
+}
+
+type filterField struct {
+	// Only rarity has an order, so only it allows <, <=, > and >=
+	ordered bool
+}
+
+var filterFields = map[string]filterField{
+	"id":       {},
+	"skill":    {},
+	"category": {},
+	"rarity":   {ordered: true},
+	"family":   {},
+	"tag":      {},
+	"source":   {},
+	"stat":     {},
+}
+
+var filterPattern = regexp.MustCompile(`^([a-z]+)(:|>=|<=|>|<|=)(.*)$`)
+
+type queryToken struct {
+	text string
+	// The token started with a quote, so it is a phrase and never a filter
+	quoted bool
+	negate bool
+	column int
+}
+
+// Parse reads a query like `skill:fishing category:water "sea creature" rarity>=rare source:net`.
+// A leading - negates a filter or term.
+func Parse(query string) (*Query, error) {
+	tokens, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, tok := range tokens {
+		match := filterPattern.FindStringSubmatch(tok.text)
+		if tok.quoted || match == nil {
+			words := tokenize(tok.text)
+			if len(words) == 0 {
+				continue
+			}
+			q.Terms = append(q.Terms, Term{Words: words, Negate: tok.negate})
+			continue
+		}
+
+		name, op, value := match[1], match[2], match[3]
+		field, known := filterFields[name]
+		if !known {
+			return nil, fmt.Errorf("%w: unknown field %q at column %d", ErrInvalidQuery, name, tok.column)
+		}
+		if op != ":" && op != "=" && !field.ordered {
+			return nil, fmt.Errorf("%w: %s can't be compared with %s at column %d", ErrInvalidQuery, name, op, tok.column)
+		}
+		filter := Filter{Field: name, Op: op, Negate: tok.negate}
+		for v := range strings.SplitSeq(value, ",") {
+			if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+				filter.Values = append(filter.Values, v)
+			}
+		}
+		if len(filter.Values) == 0 {
+			return nil, fmt.Errorf("%w: %s needs a value at column %d", ErrInvalidQuery, name, tok.column)
+		}
+		if field.ordered && op != ":" && op != "=" && len(filter.Values) > 1 {
+			return nil, fmt.Errorf("%w: %s%s takes a single value at column %d", ErrInvalidQuery, name, op, tok.column)
+		}
+		q.Filters = append(q.Filters, filter)
+	}
+	return q, nil
+}
+
+// splitQuery splits on whitespace outside of double quotes, so `source:"hunt cod"` stays whole
+func splitQuery(query string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0, 4)
+	var current strings.Builder
+	var tok queryToken
+	inQuotes, hasToken, quoteColumn := false, false, 0
+	flush := func() {
+		if hasToken {
+			tok.text = strings.ToLower(current.String())
+			tokens = append(tokens, tok)
+		}
+		current.Reset()
+		tok = queryToken{}
+		hasToken = false
+	}
+	for i, c := range []rune(query) {
+		switch {
+		case c == '"':
+			if !hasToken || (tok.negate && current.Len() == 0) {
+				tok.quoted = true
+			}
+			if !hasToken {
+				tok.column = i + 1
+			}
+			inQuotes, hasToken, quoteColumn = !inQuotes, true, i+1
+		case unicode.IsSpace(c) && !inQuotes:
+			flush()
+		case c == '-' && !hasToken:
+			tok.negate, tok.column, hasToken = true, i+1, true
+		default:
+			if !hasToken {
+				tok.column = i + 1
+			}
+			current.WriteRune(c)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated quote at column %d", ErrInvalidQuery, quoteColumn)
+	}
+	flush()
+	return tokens, nil
+}
+
+// tokenize lowercases text and splits it into words, dropping punctuation and plural s, so
+// "sea creature" finds "sea creatures"
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			words[i] = strings.TrimSuffix(word, "s")
+		}
+	}
+	return words
+}
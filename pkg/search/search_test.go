@@ -0,0 +1,76 @@
+package search
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+const testShardDataLocation = "../../data/shards.json"
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`skill:fishing category:water "sea creature" rarity>=rare source:net -family:squid,eel`)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(q.Filters) != 5 || len(q.Terms) != 1 || !slices.Equal(q.Terms[0].Words, []string{"sea", "creature"}) {
+		t.Errorf("Unexpected query: %+v", q)
+	}
+	if f := q.Filters[4]; !f.Negate || !slices.Equal(f.Values, []string{"squid", "eel"}) {
+		t.Errorf("Unexpected family filter: %+v", f)
+	}
+
+	for _, bad := range []string{`color:red`, `skill>fishing`, `"sea creature`, `family:`} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected an invalid query, got %v", bad, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	shardData, err := shards.ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	idx := NewIndex(shardData)
+	if _, err := idx.Search(`rarity>=mythic`); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected an unknown rarity to be rejected, got %v", err)
+	}
+
+	tests := []struct {
+		query string
+		first string
+		count int
+	}{
+		// A name match outranks the same words in an effect
+		{`sea creature`, "C14", -1},
+		{`"sea creature" category:water`, "C14", 4},
+		{`grove`, "C1", 1},
+		{`stat:strength rarity>=rare`, "R3", 4},
+		{`source:"hunt cod"`, "C5", 1},
+		{`skill:fishing source:net`, "C5", -1},
+		// No words to look for in the sources, and no source type by that name
+		{`source:-`, "", 0},
+		{`strength -rarity:common,uncommon -stat:strength`, "", 0},
+	}
+	for _, tt := range tests {
+		results, err := idx.Search(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if tt.count >= 0 && len(results) != tt.count {
+			t.Errorf("%s: expected %d results, got %d", tt.query, tt.count, len(results))
+		}
+		if tt.first != "" && (len(results) == 0 || results[0].Shard.ID != tt.first) {
+			t.Errorf("%s: expected %s first, got %v", tt.query, tt.first, results)
+		}
+		for _, r := range results {
+			if tt.query == `skill:fishing source:net` && r.Shard.Skill != "fishing" {
+				t.Errorf("%s: %s is not a fishing shard", tt.query, r.Shard.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+// Server serves the shard calculator along with the processed shard data and live bazaar prices
+type Server struct {
+	data      *shards.ProcessedShardData
+	shardJSON []byte
+	// The built app, nil if the binary was built without it
+	ui fs.FS
+
+	mu     sync.RWMutex
+	prices *shards.PriceSheet
+}
+
+func New(data *shards.ProcessedShardData, ui fs.FS) (*Server, error) {
+	shardJSON, err := shards.MarshalShardData(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{data: data, shardJSON: shardJSON, ui: ui}, nil
+}
+
+// SetPrices replaces the quotes served, keyed by bazaar ID. Shards without a quote are
+// served an estimate.
+func (s *Server) SetPrices(quotes map[string]float64, at time.Time) {
+	sheet := s.data.NewPriceSheet(&prices.Snapshot{Time: at, Prices: quotes})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices = sheet
+}
+
+// RefreshPrices gets prices from provider right away and then every interval until ctx is done.
+// A failed refresh keeps the previous prices.
+func (s *Server) RefreshPrices(ctx context.Context, interval time.Duration, provider prices.PriceProvider) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if sheet, err := s.data.LoadPriceSheet(ctx, provider); err != nil {
+			log.Printf("Error refreshing prices: %v", err)
+		} else {
+			s.mu.Lock()
+			s.prices = sheet
+			s.mu.Unlock()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/prices", s.servePrices)
+	mux.HandleFunc("GET /api/shards", s.serveShards)
+	mux.HandleFunc("GET /", s.serveUI)
+	return mux
+}
+
+// servePrices answers with the same price sheet the pricing step writes to shard_prices.json
+func (s *Server) servePrices(w http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	prices := s.prices
+	s.mu.RUnlock()
+	if prices == nil {
+		http.Error(w, "prices have not been fetched yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(prices); err != nil {
+		log.Printf("Error writing prices: %v", err)
+	}
+}
+
+func (s *Server) serveShards(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.shardJSON)
+}
+
+func (s *Server) serveUI(w http.ResponseWriter, req *http.Request) {
+	if s.ui == nil {
+		http.Error(w, "this binary was built without the web app, rebuild it with -tags embedui", http.StatusNotFound)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean(req.URL.Path), "/")
+	if info, err := fs.Stat(s.ui, name); err != nil || info.IsDir() {
+		// Routes like /C1 and /about belong to the app, but a missing asset is still missing
+		if path.Ext(name) != "" {
+			http.NotFound(w, req)
+			return
+		}
+		name = "index.html"
+	}
+	http.ServeFileFS(w, req, s.ui, name)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+	"github.com/andu2/andu-skyblock-tools/pkg/shards"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	shardData, err := shards.ProcessShards("../../data/shards.json")
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	ui := fstest.MapFS{
+		"index.html":      {Data: []byte("<div id=\"root\"></div>")},
+		"assets/index.js": {Data: []byte("render()")},
+		"img/coins1.png":  {Data: []byte("png")},
+	}
+	srv, err := New(shardData, ui)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	if status, _ := get(t, ts.URL+"/api/prices"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before prices are fetched, got %d", status)
+	}
+	go srv.RefreshPrices(t.Context(), time.Hour, prices.Static{"SHARD_GRIFFIN": 1000})
+	status, body := get(t, ts.URL+"/api/prices")
+	for deadline := time.Now().Add(time.Second); status != http.StatusOK && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		status, body = get(t, ts.URL+"/api/prices")
+	}
+	var sheet shards.PriceSheet
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &sheet) != nil || sheet.ShardPrices["SHARD_GRIFFIN"] != 1000 {
+		t.Errorf("Unexpected prices response %d: %s", status, body)
+	}
+
+	status, body = get(t, ts.URL+"/api/shards")
+	var served struct {
+		Shards map[string]*shards.Shard `json:"shards"`
+	}
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &served) != nil || len(served.Shards) != len(shardData.Shards) {
+		t.Errorf("Unexpected shards response %d", status)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/", http.StatusOK, "root"},
+		{"/C1", http.StatusOK, "root"},
+		{"/about", http.StatusOK, "root"},
+		{"/assets", http.StatusOK, "root"},
+		{"/assets/index.js", http.StatusOK, "render()"},
+		{"/assets/missing.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		status, body := get(t, ts.URL+tt.path)
+		if status != tt.status || !strings.Contains(body, tt.body) {
+			t.Errorf("%s: expected %d containing %q, got %d: %s", tt.path, tt.status, tt.body, status, body)
+		}
+	}
+}
+
+func TestRefreshKeepsPricesOnError(t *testing.T) {
+	srv := &Server{data: &shards.ProcessedShardData{}}
+	srv.SetPrices(map[string]float64{"SHARD_GRIFFIN": 1000}, time.Unix(100, 0))
+	fetched := make(chan struct{})
+	go srv.RefreshPrices(t.Context(), time.Hour, prices.ProviderFunc(func(ctx context.Context) (*prices.Snapshot, error) {
+		defer close(fetched)
+		return nil, errors.New("bazaar is down")
+	}))
+	<-fetched
+	srv.mu.RLock()
+	defer srv.mu.RUnlock()
+	if srv.prices.Timestamp != 100 || srv.prices.ShardPrices["SHARD_GRIFFIN"] != 1000 {
+		t.Errorf("A failed fetch should keep the previous prices, got %+v", srv.prices)
+	}
+}
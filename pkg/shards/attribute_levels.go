@@ -0,0 +1,212 @@
+package shards
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const MaxAttributeLevel = 10
+
+// Shards needed for each attribute level, by rarity. These add up to the default costToMax, and
+// are scaled to whatever costToMax the shard data sets.
+var levelCostCurves = map[rarity][]int{
+	rarityCommon:    {1, 4, 5, 6, 7, 8, 10, 14, 17, 24},
+	rarityUncommon:  {1, 3, 3, 4, 5, 6, 8, 9, 11, 14},
+	rarityRare:      {1, 3, 3, 4, 4, 5, 6, 6, 7, 9},
+	rarityEpic:      {1, 2, 2, 3, 3, 3, 4, 4, 5, 5},
+	rarityLegendary: {1, 1, 2, 2, 2, 3, 3, 3, 3, 4},
+}
+
+// scaleLevelCurve scales the curve to add up to total, handing out the rounding remainder to the
+// levels that lost the most to rounding down
+func scaleLevelCurve(curve []int, total int) []int {
+	curveTotal := 0
+	for _, n := range curve {
+		curveTotal += n
+	}
+	if total <= 0 || total == curveTotal {
+		return slices.Clone(curve)
+	}
+
+	scaled := make([]int, len(curve))
+	remainders := make([]int, len(curve))
+	assigned := 0
+	for i, n := range curve {
+		scaled[i] = n * total / curveTotal
+		remainders[i] = n * total % curveTotal
+		assigned += scaled[i]
+	}
+	order := make([]int, len(curve))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(remainders[b], remainders[a])
+	})
+	for _, i := range order[:total-assigned] {
+		scaled[i]++
+	}
+	return scaled
+}
+
+type AttributeLevel struct {
+	Level int `json:"level"`
+	// Shards needed to go from the previous level to this one, and from nothing to this one
+	Shards      int     `json:"shards"`
+	TotalShards int     `json:"totalShards"`
+	Effect      float64 `json:"effect"`
+	Effect2     float64 `json:"effect2,omitempty"`
+	Description string  `json:"description"`
+}
+
+// AttributeLevels lists every level of a shard's attribute. Effects grow linearly, so level 1
+// gives a tenth of the max.
+func (d *ProcessedShardData) AttributeLevels(id string) ([]AttributeLevel, error) {
+	s, exists := d.Shards[id]
+	if !exists {
+		return nil, errorOf(ErrUnknownShard, "unknown shard ID: %s", id)
+	}
+	costs := d.LevelCosts[s.Rarity]
+	levels := make([]AttributeLevel, 0, MaxAttributeLevel)
+	total := 0
+	for level := 1; level <= MaxAttributeLevel; level++ {
+		total += costs[level-1]
+		effect, effect2 := s.EffectAt(level)
+		levels = append(levels, AttributeLevel{
+			Level:       level,
+			Shards:      costs[level-1],
+			TotalShards: total,
+			Effect:      effect,
+			Effect2:     effect2,
+			Description: s.RenderEffect(level),
+		})
+	}
+	return levels, nil
+}
+
+// EffectAt returns both effect values at the given level, 0 being no attribute at all
+func (s *Shard) EffectAt(level int) (float64, float64) {
+	level = min(max(level, 0), MaxAttributeLevel)
+	scale := func(effectMax float64) float64 {
+		// Rounded so a tenth of 0.3 doesn't come out as 0.030000000000000002
+		return math.Round(effectMax*float64(level)/MaxAttributeLevel*1e4) / 1e4
+	}
+	return scale(s.EffectMax), scale(s.Effect2Max)
+}
+
+// RenderEffect fills the effect placeholders of the description with the values at level
+func (s *Shard) RenderEffect(level int) string {
+	effect, effect2 := s.EffectAt(level)
+	return strings.NewReplacer(
+		"{{effect}}", strconv.FormatFloat(effect, 'f', -1, 64),
+		"{{effect2}}", strconv.FormatFloat(effect2, 'f', -1, 64),
+	).Replace(s.EffectDescription)
+}
+
+type Upgrade struct {
+	ID        string `json:"id"`
+	FromLevel int    `json:"fromLevel"`
+	ToLevel   int    `json:"toLevel"`
+	Shards    int    `json:"shards"`
+	// The cheaper of buying the shard and fusing it, per shard
+	UnitPrice float64 `json:"unitPrice"`
+	// Fused when fusing beats the bazaar price
+	Fused      bool    `json:"fused"`
+	Cost       float64 `json:"cost"`
+	EffectGain float64 `json:"effectGain"`
+	// Stat gained per million coins. Only comparable between shards that boost the same stat.
+	GainPerMillion float64 `json:"gainPerMillion"`
+}
+
+type unitPrice struct {
+	price float64
+	fused bool
+}
+
+// unitPrices is the cheaper of buying and fusing each shard, for shards that can be priced at all
+func (d *ProcessedShardData) unitPrices(prices map[string]float64) map[string]unitPrice {
+	cheapestFusions := d.CheapestFusions(prices)
+	units := make(map[string]unitPrice, len(d.Shards))
+	for id, s := range d.Shards {
+		unit := unitPrice{price: prices[s.BazaarId]}
+		if path, ok := cheapestFusions[id]; ok && (unit.price <= 0 || path.PricePerShard < unit.price) {
+			unit = unitPrice{price: path.PricePerShard, fused: true}
+		}
+		if unit.price > 0 {
+			units[id] = unit
+		}
+	}
+	return units
+}
+
+// NextUpgrades prices the next level of every shard that isn't maxed, best stat per coin first.
+// Levels are keyed by shard ID, missing shards are at level 0. Prices are keyed by bazaar ID.
+func (d *ProcessedShardData) NextUpgrades(levels map[string]int, prices map[string]float64) []Upgrade {
+	unitPrices := d.unitPrices(prices)
+	upgrades := make([]Upgrade, 0, len(d.Shards))
+	for id, s := range d.Shards {
+		level := levels[id]
+		if level >= MaxAttributeLevel {
+			continue
+		}
+		unit, ok := unitPrices[id]
+		if !ok {
+			continue
+		}
+
+		shards := d.LevelCosts[s.Rarity][level]
+		before, _ := s.EffectAt(level)
+		after, _ := s.EffectAt(level + 1)
+		upgrade := Upgrade{
+			ID:         id,
+			FromLevel:  level,
+			ToLevel:    level + 1,
+			Shards:     shards,
+			UnitPrice:  unit.price,
+			Fused:      unit.fused,
+			Cost:       unit.price * float64(shards),
+			EffectGain: after - before,
+		}
+		if upgrade.Cost > 0 {
+			upgrade.GainPerMillion = upgrade.EffectGain / upgrade.Cost * 1e6
+		}
+		upgrades = append(upgrades, upgrade)
+	}
+	slices.SortFunc(upgrades, func(a, b Upgrade) int {
+		return cmp.Or(cmp.Compare(b.GainPerMillion, a.GainPerMillion), cmp.Compare(a.ID, b.ID))
+	})
+	return upgrades
+}
+
+// ParseLevels reads levels written as shard=level pairs, like "C1=3,Grove=10". Shards are
+// looked up with resolver, so anything it accepts works; the levels are keyed by shard ID.
+func ParseLevels(s string, resolver *Resolver) (map[string]int, error) {
+	levels := make(map[string]int)
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, levelText, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected shard=level, got %q", pair)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(levelText))
+		if err != nil || level < 0 || level > MaxAttributeLevel {
+			return nil, fmt.Errorf("level for %s must be between 0 and %d", id, MaxAttributeLevel)
+		}
+		shard, err := resolver.ResolveOne(strings.TrimSpace(id))
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := levels[shard.ID]; exists {
+			return nil, fmt.Errorf("level for %s (%s) is given twice", shard.Name, shard.ID)
+		}
+		levels[shard.ID] = level
+	}
+	return levels, nil
+}
@@ -0,0 +1,82 @@
+package shards
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestScaleLevelCurve(t *testing.T) {
+	for r, curve := range levelCostCurves {
+		for _, total := range []int{0, 10, 24, 48, 96, 150} {
+			scaled := scaleLevelCurve(curve, total)
+			sum := 0
+			for _, n := range scaled {
+				sum += n
+			}
+			expected := total
+			if total == 0 {
+				expected = 0
+				for _, n := range curve {
+					expected += n
+				}
+			}
+			if sum != expected {
+				t.Errorf("%s scaled to %d adds up to %d", r, total, sum)
+			}
+		}
+	}
+}
+
+func TestAttributeLevels(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	for r, costs := range shardData.LevelCosts {
+		if !slices.Equal(costs, levelCostCurves[r]) {
+			t.Errorf("Default costToMax should give the known %s level costs, got %v", r, costs)
+		}
+	}
+
+	levels, err := shardData.AttributeLevels("C1")
+	if err != nil {
+		t.Fatalf("Failed to get levels: %v", err)
+	}
+	if len(levels) != MaxAttributeLevel || levels[0].Description != "+2 health" || levels[9].TotalShards != 96 {
+		t.Errorf("Unexpected levels for C1: %+v", levels)
+	}
+	if got := shardData.Shards["R7"].RenderEffect(5); got != "The effects of beacons are 50% stronger on you. 0.5% chance to find signal enhancers in tree gifts" {
+		t.Errorf("Unexpected R7 effect at level 5: %s", got)
+	}
+
+	resolver, err := NewResolver(shardData, nil)
+	if err != nil {
+		t.Fatalf("Failed to build resolver: %v", err)
+	}
+	parsed, err := ParseLevels("c2=9, Grove=3", resolver)
+	if err != nil || !maps.Equal(parsed, map[string]int{"C2": 9, "C1": 3}) {
+		t.Errorf("Expected levels keyed by shard ID, got %v, %v", parsed, err)
+	}
+	if _, err := ParseLevels("xyzzy=3", resolver); !errors.Is(err, ErrUnknownShard) {
+		t.Errorf("Expected ErrUnknownShard for a level of no shard, got %v", err)
+	}
+	if _, err := ParseLevels("C1=3,grove=4", resolver); err == nil {
+		t.Error("Expected an error for a shard given twice")
+	}
+
+	prices := map[string]float64{
+		shardData.Shards["C2"].BazaarId: 1000,
+		shardData.Shards["C3"].BazaarId: 1000,
+	}
+	upgrades := shardData.NextUpgrades(map[string]int{"C2": 9}, prices)
+	i2 := slices.IndexFunc(upgrades, func(u Upgrade) bool { return u.ID == "C2" })
+	i3 := slices.IndexFunc(upgrades, func(u Upgrade) bool { return u.ID == "C3" })
+	if i2 < 0 || i3 < 0 || i3 > i2 {
+		t.Fatalf("Expected a fresh C3 to rank above a nearly maxed C2, got %v", upgrades)
+	}
+	if upgrades[i2].Shards != 24 || upgrades[i2].Cost != 24000 || upgrades[i2].EffectGain != 1 {
+		t.Errorf("Unexpected C2 upgrade: %+v", upgrades[i2])
+	}
+}
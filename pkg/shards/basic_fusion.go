@@ -1,6 +1,6 @@
 package shards
 
-func addBasicFusionTargets(shards map[string]*shard) {
+func addBasicFusionTargets(shards map[string]*Shard) {
 	sortedShards := getSortedShards(shards)
 
 	for i := 0; i < len(sortedShards); i++ {
@@ -0,0 +1,123 @@
+package shards
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CatalogItem is a shard item as the game lists it. Names keep their " Shard" suffix.
+type CatalogItem struct {
+	// The item ID, which is also the bazaar ID
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Rarity string `json:"rarity"`
+}
+
+type CatalogMismatch struct {
+	ID     string `json:"id"`
+	Field  string `json:"field"`
+	Ours   string `json:"ours"`
+	Theirs string `json:"theirs"`
+}
+
+type CatalogReport struct {
+	Mismatches []CatalogMismatch `json:"mismatches"`
+	// Shards that match no item by bazaar ID or name, by shard ID
+	Unlisted []string `json:"unlisted"`
+	// Items that no shard matches
+	Missing []CatalogItem `json:"missing"`
+}
+
+func (r *CatalogReport) IsEmpty() bool {
+	return len(r.Mismatches) == 0 && len(r.Unlisted) == 0 && len(r.Missing) == 0
+}
+
+var formattingCodePattern = regexp.MustCompile("§.")
+
+// catalogName is the shard name an item name stands for
+func catalogName(name string) string {
+	name = strings.TrimSpace(formattingCodePattern.ReplaceAllString(name, ""))
+	return strings.TrimSuffix(name, " Shard")
+}
+
+// CheckCatalog compares bazaar IDs, names and rarities with the game's item list. A shard whose
+// bazaar ID isn't listed is matched by name instead, so the report says which ID it should have.
+func (d *ProcessedShardData) CheckCatalog(items []CatalogItem) *CatalogReport {
+	byID := make(map[string]CatalogItem, len(items))
+	byName := make(map[string]CatalogItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+		byName[strings.ToLower(catalogName(item.Name))] = item
+	}
+
+	report := &CatalogReport{
+		Mismatches: make([]CatalogMismatch, 0),
+		Unlisted:   make([]string, 0),
+		Missing:    make([]CatalogItem, 0),
+	}
+	matched := make(map[string]bool, len(items))
+	for _, s := range getSortedShards(d.Shards) {
+		item, ok := byID[s.BazaarId]
+		if !ok {
+			if item, ok = byName[strings.ToLower(s.Name)]; !ok {
+				report.Unlisted = append(report.Unlisted, s.ID)
+				continue
+			}
+			report.Mismatches = append(report.Mismatches, CatalogMismatch{s.ID, "bazaarId", s.BazaarId, item.ID})
+		}
+		matched[item.ID] = true
+		if name := catalogName(item.Name); name != s.Name {
+			report.Mismatches = append(report.Mismatches, CatalogMismatch{s.ID, "name", s.Name, name})
+		}
+		if item.Rarity != string(s.Rarity) {
+			report.Mismatches = append(report.Mismatches, CatalogMismatch{s.ID, "rarity", string(s.Rarity), item.Rarity})
+		}
+	}
+
+	for _, item := range items {
+		if !matched[item.ID] {
+			report.Missing = append(report.Missing, item)
+		}
+	}
+	slices.SortFunc(report.Missing, func(a, b CatalogItem) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return report
+}
+
+// WriteShardStubs writes a shard data fragment with an entry for each item, numbered after the
+// last shard of its rarity. Only the name and bazaar ID are filled in; the fragment won't process
+// until someone adds the rest. Returns the IDs the stubs were given.
+func (d *ProcessedShardData) WriteShardStubs(items []CatalogItem, out string) ([]string, error) {
+	format, err := FormatForPath(out)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[rarity]int)
+	for _, s := range d.Shards {
+		last[s.Rarity] = max(last[s.Rarity], s.Number)
+	}
+
+	stubs := make(map[string]shardConfigData, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		r, err := d.scheme.parseRarity(item.Rarity)
+		if err != nil {
+			return nil, errorOf(ErrInvalidShardData, "item %s: %w", item.ID, err)
+		}
+		last[r]++
+		id := d.scheme.shardID(r, last[r])
+		stubs[id] = shardConfigData{Name: catalogName(item.Name), BazaarId: item.ID}
+		ids = append(ids, id)
+	}
+
+	fragment := struct {
+		Shards map[string]shardConfigData `json:"shards"`
+	}{stubs}
+	if err := writeConfigDocument(&fragment, out, format); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,57 @@
+package shards
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckCatalog(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	items := []CatalogItem{
+		{ID: "SHARD_GROVE", Name: "Grove Shard", Rarity: "common"},
+		{ID: "SHARD_MIST", Name: "§fMists Shard", Rarity: "common"},
+		{ID: "SHARD_FLASH_NEW", Name: "Flash Shard", Rarity: "uncommon"},
+		{ID: "SHARD_NEWCOMER", Name: "Newcomer Shard", Rarity: "rare"},
+	}
+	report := shardData.CheckCatalog(items)
+
+	expected := []CatalogMismatch{
+		{"C2", "name", "Mist", "Mists"},
+		{"C3", "bazaarId", "SHARD_FLASH", "SHARD_FLASH_NEW"},
+		{"C3", "rarity", "common", "uncommon"},
+	}
+	if !slices.Equal(report.Mismatches, expected) {
+		t.Errorf("Expected mismatches %v, got %v", expected, report.Mismatches)
+	}
+	if len(report.Unlisted) != len(shardData.Shards)-3 || slices.Contains(report.Unlisted, "C1") {
+		t.Errorf("Expected every shard but C1 to C3 to be unlisted, got %v", report.Unlisted)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].ID != "SHARD_NEWCOMER" {
+		t.Fatalf("Expected SHARD_NEWCOMER to be missing, got %v", report.Missing)
+	}
+
+	out := filepath.Join(t.TempDir(), "new_shards.json")
+	ids, err := shardData.WriteShardStubs(report.Missing, out)
+	if err != nil {
+		t.Fatalf("Failed to write stubs: %v", err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fragment struct {
+		Shards map[string]shardConfigData `json:"shards"`
+	}
+	if err := json.Unmarshal(content, &fragment); err != nil {
+		t.Fatalf("Failed to read stubs: %v", err)
+	}
+	if !slices.Equal(ids, []string{"R62"}) || fragment.Shards["R62"].Name != "Newcomer" || fragment.Shards["R62"].BazaarId != "SHARD_NEWCOMER" {
+		t.Errorf("Expected a stub for R62, got %v: %s", ids, content)
+	}
+}
@@ -0,0 +1,349 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Shard data can be authored as JSON, YAML or TOML. Every format is converted to JSON before it
+// is unmarshaled, so the json tags on shardConfig are the only schema.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+var configExtensions = map[string]string{
+	".json": FormatJSON,
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".toml": FormatTOML,
+}
+
+// FormatForPath picks the authoring format from a file extension
+func FormatForPath(path string) (string, error) {
+	format, ok := configExtensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", errorOf(ErrInvalidShardData, "unsupported shard data format: %s", path)
+	}
+	return format, nil
+}
+
+// readConfigJSON returns the shard data at path as a single JSON document. A directory is read
+// as one document split across its files: the shards are merged and each other setting may
+// only be defined once.
+func readConfigJSON(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard data file: %w", err)
+	}
+	if !info.IsDir() {
+		format, err := FormatForPath(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shard data file: %w", err)
+		}
+		if format == FormatJSON {
+			return data, nil
+		}
+		doc, err := decodeConfigDocument(data, format)
+		if err != nil {
+			return nil, errorOf(ErrInvalidShardData, "failed to parse %s: %w", path, err)
+		}
+		return json.Marshal(doc)
+	}
+
+	files, err := configFilesInDir(path)
+	if err != nil {
+		return nil, err
+	}
+	merged := map[string]any{}
+	shards := map[string]any{}
+	for _, file := range files {
+		format, _ := FormatForPath(file)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shard data file: %w", err)
+		}
+		doc, err := decodeConfigDocument(data, format)
+		if err != nil {
+			return nil, errorOf(ErrInvalidShardData, "failed to parse %s: %w", file, err)
+		}
+		for key, value := range doc {
+			if key != "shards" {
+				if _, exists := merged[key]; exists {
+					return nil, errorOf(ErrInvalidShardData, "%s redefines %s", file, key)
+				}
+				merged[key] = value
+				continue
+			}
+			fileShards, ok := value.(map[string]any)
+			if !ok {
+				return nil, errorOf(ErrInvalidShardData, "shards in %s must be a table of shard ID to shard", file)
+			}
+			for id, shard := range fileShards {
+				if _, exists := shards[id]; exists {
+					return nil, errorOf(ErrInvalidShardData, "%s redefines shard %s", file, id)
+				}
+				shards[id] = shard
+			}
+		}
+	}
+	merged["shards"] = shards
+	return json.Marshal(merged)
+}
+
+func configFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard data directory: %w", err)
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := FormatForPath(entry.Name()); err == nil {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	slices.Sort(files)
+	if len(files) == 0 {
+		return nil, errorOf(ErrInvalidShardData, "no shard data files in %s", dir)
+	}
+	return files, nil
+}
+
+func decodeConfigDocument(data []byte, format string) (map[string]any, error) {
+	doc := map[string]any{}
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	return doc, nil
+}
+
+// encodeConfigDocument writes a JSON document in another format. YAML keeps the key order of
+// the JSON, TOML sorts keys since its tables can't be reordered freely anyway.
+func encodeConfigDocument(jsonData []byte, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		var out bytes.Buffer
+		if err := json.Indent(&out, jsonData, "", "    "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	case FormatYAML:
+		// JSON is valid YAML, so parsing it as a node tree keeps the order; only the styles
+		// need resetting to get block YAML back out
+		var node yaml.Node
+		if err := yaml.Unmarshal(jsonData, &node); err != nil {
+			return nil, err
+		}
+		resetYAMLStyle(&node)
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case FormatTOML:
+		doc, err := decodeConfigDocument(jsonData, FormatJSON)
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		encoder := toml.NewEncoder(&out)
+		encoder.Indent = ""
+		if err := encoder.Encode(tomlValue(doc)); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// tomlValue converts decoded JSON into values the TOML encoder understands. TOML has no null,
+// and json.Number would otherwise be written as a string.
+func tomlValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if value != nil {
+				out[key] = tomlValue(value)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, value := range v {
+			if value != nil {
+				out = append(out, tomlValue(value))
+			}
+		}
+		return out
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+type ConvertOptions struct {
+	Format string
+	// Rewrite every special fuse requirement as a canonical expression
+	Expressions bool
+}
+
+// ConvertShardData rewrites shard data in another format. If out is a directory, the shards are
+// split into one file per rarity next to a settings file holding everything else. Settings and
+// rarity files left in the directory from converting to another format are removed; other files
+// are left alone.
+func ConvertShardData(in string, out string, opts ConvertOptions) error {
+	config, err := loadShardConfig(in)
+	if err != nil {
+		return fmt.Errorf("error loading shard config: %w", err)
+	}
+	if opts.Expressions {
+		if err := rewriteRequirementsAsExpressions(config); err != nil {
+			return err
+		}
+	}
+	return writeShardConfig(config, out, opts.Format)
+}
+
+func writeShardConfig(config *shardConfig, out string, format string) error {
+	if info, err := os.Stat(out); err == nil && info.IsDir() {
+		return writeShardConfigDir(config, out, format)
+	}
+	return writeConfigDocument(config, out, format)
+}
+
+func writeShardConfigDir(config *shardConfig, dir string, format string) error {
+	scheme, err := newShardScheme(config)
+	if err != nil {
+		return err
+	}
+	ext := "." + format
+	byRarity := make(map[rarity]map[string]shardConfigData)
+	for id, data := range config.Shards {
+		r, _, err := scheme.parseID(id)
+		if err != nil {
+			return err
+		}
+		if byRarity[r] == nil {
+			byRarity[r] = make(map[string]shardConfigData)
+		}
+		byRarity[r][id] = data
+	}
+
+	settings := *config
+	settings.Shards = nil
+	written := map[string]bool{"settings" + ext: true}
+	if err := writeConfigDocument(&settings, filepath.Join(dir, "settings"+ext), format); err != nil {
+		return err
+	}
+	for r, shards := range byRarity {
+		fragment := struct {
+			Shards map[string]shardConfigData `json:"shards"`
+		}{shards}
+		written[string(r)+ext] = true
+		if err := writeConfigDocument(&fragment, filepath.Join(dir, string(r)+ext), format); err != nil {
+			return err
+		}
+	}
+
+	// Files from converting to another format would be read back along with these, so they have
+	// to go. Only names this writes are touched, since the directory may hold anything else too.
+	names := []string{"settings"}
+	for _, def := range scheme.rarities {
+		names = append(names, string(def.ID))
+	}
+	for _, name := range names {
+		for other := range configExtensions {
+			if written[name+other] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name+other)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove stale shard data: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeConfigDocument(doc any, path string, format string) error {
+	// Without this the & in requirement expressions would be written as \u0026
+	var jsonData bytes.Buffer
+	encoder := json.NewEncoder(&jsonData)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error formatting JSON: %w", err)
+	}
+	data, err := encodeConfigDocument(jsonData.Bytes(), format)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", format, err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+func rewriteRequirementsAsExpressions(config *shardConfig) error {
+	for id, data := range config.Shards {
+		fuses := slices.Clone(data.SpecialFuses)
+		for i := range fuses {
+			for _, req := range []*specialFuseRequirement{&fuses[i].Requirement1, &fuses[i].Requirement2} {
+				expr, err := req.parsed()
+				if err != nil {
+					return &ShardError{ID: id, Op: "rewriting special fuses", Err: err}
+				}
+				*req = specialFuseRequirement{Expr: expr.format(), expr: expr}
+			}
+		}
+		data.SpecialFuses = fuses
+		config.Shards[id] = data
+	}
+	return nil
+}
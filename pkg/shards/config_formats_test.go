@@ -0,0 +1,76 @@
+package shards
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConvertShardDataRoundTrip(t *testing.T) {
+	original, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "shards."+format)
+			if err := ConvertShardData(testShardDataLocation, file, ConvertOptions{Format: format}); err != nil {
+				t.Fatalf("Failed to convert to file: %v", err)
+			}
+			split := filepath.Join(dir, "split")
+			if err := os.Mkdir(split, 0755); err != nil {
+				t.Fatal(err)
+			}
+			// Left over from converting an older version in another format
+			for _, stale := range []string{"common.yml", "legendary.json"} {
+				if err := os.WriteFile(filepath.Join(split, stale), []byte(`{"shards": {"C999": {}}}`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Not shard data this writes, so it has to survive
+			unrelated := filepath.Join(split, "notes.json")
+			if err := os.WriteFile(unrelated, []byte(`{}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ConvertShardData(file, split, ConvertOptions{Format: format}); err != nil {
+				t.Fatalf("Failed to convert to directory: %v", err)
+			}
+			if _, err := os.Stat(unrelated); err != nil {
+				t.Errorf("Expected unrelated files to be kept: %v", err)
+			}
+			back := filepath.Join(dir, "back.json")
+			if err := ConvertShardData(split, back, ConvertOptions{Format: FormatJSON}); err != nil {
+				t.Fatalf("Failed to convert back to JSON: %v", err)
+			}
+
+			for _, path := range []string{file, split, back} {
+				loaded, err := loadShardConfig(path)
+				if err != nil {
+					t.Fatalf("Failed to load %s: %v", path, err)
+				}
+				if !reflect.DeepEqual(original, loaded) {
+					t.Errorf("%s does not match the original shard data", path)
+				}
+			}
+		})
+	}
+}
+
+func TestShardDataDirRejectsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml": "specialFuseMultiplier: 2\nshards:\n  C1:\n    name: Grove\n",
+		"b.toml": "[shards.C1]\nname = \"Grove\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := loadShardConfig(dir); err == nil {
+		t.Error("Expected error for shard defined in two files")
+	}
+}
@@ -1,27 +1,108 @@
 package shards
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 )
 
-func DumpShardData(inFile string, outFile string) {
-	shards, err := processShards(inFile)
+func DumpShardData(inFile string, outFile string, opts OutputOptions) error {
+	shards, err := ProcessShards(inFile)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	return WriteShardData(shards, outFile, opts)
+}
 
+// WriteShardData writes the front-end view of processed data, followed by its manifest. Files are
+// replaced atomically so a dev server watching them never reads a partial write, and chunks are
+// written before the index that points at them. The data passed in is not modified.
+func WriteShardData(data *ProcessedShardData, outFile string, opts OutputOptions) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	separateFusions := opts.Layout == LayoutSplit || opts.Encoding == EncodingCompact
+	index := outputIndex{ProcessedShardData: frontEndView(data, separateFusions)}
+	var chunkHashes map[string]string
+	if separateFusions {
+		index.Output = &outputInfo{Layout: opts.Layout, Encoding: opts.Encoding}
+		fusions := make(map[string]any, len(data.Shards))
+		if opts.Encoding == EncodingCompact {
+			interner := newFusionInterner(data.Shards, index.Output)
+			for _, s := range interner.shards {
+				fusions[s.ID] = interner.compact(s)
+			}
+		} else {
+			for id, s := range data.Shards {
+				fusions[id] = s.FuseCombinations
+			}
+		}
+
+		if opts.Layout == LayoutSplit {
+			index.Output.FusionChunks = chunkDir(outFile) + "/{id}" + filepath.Ext(outFile)
+			if chunkHashes, err = writeChunks(outFile, fusions, opts); err != nil {
+				return err
+			}
+		} else {
+			index.Fusions = make(map[string]*compactFusions, len(fusions))
+			for id, f := range fusions {
+				index.Fusions[id] = f.(*compactFusions)
+			}
+		}
+	}
+
+	content, err := encodeOutput(&index, opts)
+	if err != nil {
+		return fmt.Errorf("error formatting output: %w", err)
+	}
+	if err := writeFileAtomic(outFile, content); err != nil {
+		return err
+	}
+	return writeManifest(outFile, data.inputHash, content, chunkHashes)
+}
+
+// MarshalShardData encodes the front-end view of processed data the way WriteShardData writes it
+// by default
+func MarshalShardData(data *ProcessedShardData) ([]byte, error) {
+	return encodeOutput(&outputIndex{ProcessedShardData: frontEndView(data, false)}, OutputOptions{})
+}
+
+func frontEndView(data *ProcessedShardData, withoutFusions bool) *ProcessedShardData {
 	// Remove special fuse details from the front-end view - only the text description is required
-	for _, shard := range shards.Shards {
-		shard.SpecialFuses = nil
+	view := *data
+	view.Shards = make(map[string]*Shard, len(data.Shards))
+	for id, shard := range data.Shards {
+		stripped := *shard
+		stripped.SpecialFuses = nil
+		if withoutFusions {
+			stripped.FuseCombinations = nil
+		}
+		view.Shards[id] = &stripped
 	}
+	return &view
+}
 
-	processedShardJson, err := json.MarshalIndent(shards, "", "  ")
+func writeFileAtomic(outFile string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(outFile), filepath.Base(outFile)+".*.tmp")
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("error creating temp file: %w", err)
 	}
+	defer os.Remove(tmp.Name())
 
-	if err := os.WriteFile(outFile, processedShardJson, 0644); err != nil {
-		panic(err)
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing temp file: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error setting file mode: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), outFile); err != nil {
+		return fmt.Errorf("error replacing %s: %w", outFile, err)
 	}
+	return nil
 }
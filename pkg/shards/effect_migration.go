@@ -0,0 +1,72 @@
+package shards
+
+import (
+	"fmt"
+	"slices"
+)
+
+type EffectReview struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+type EffectMigration struct {
+	// Shards that already listed their effects
+	Structured []string `json:"structured"`
+	// Shards whose description was parsed into effects
+	Migrated []string `json:"migrated"`
+	// Shards whose description couldn't be parsed, to be written by hand
+	NeedsReview []EffectReview `json:"needsReview"`
+}
+
+// MigrateEffects parses the effect description of every shard that doesn't list its effects yet
+// and writes the parsed effects into the shard data at out, in the given format. Nothing is written
+// if out is empty.
+func MigrateEffects(in string, out string, format string) (*EffectMigration, error) {
+	config, err := loadShardConfig(in)
+	if err != nil {
+		return nil, fmt.Errorf("error loading shard config: %w", err)
+	}
+
+	scheme, err := newShardScheme(config)
+	if err != nil {
+		return nil, err
+	}
+	migration := &EffectMigration{}
+	for id, data := range config.Shards {
+		if len(data.Effects) > 0 {
+			migration.Structured = append(migration.Structured, id)
+			continue
+		}
+		stats, ok, _ := effectStatsFor(data, config)
+		if !ok {
+			migration.NeedsReview = append(migration.NeedsReview, EffectReview{ID: id, Description: data.EffectDescription})
+			continue
+		}
+		for i := range stats {
+			stats[i].Max = 0
+		}
+		data.Effects = stats
+		config.Shards[id] = data
+		migration.Migrated = append(migration.Migrated, id)
+	}
+
+	// Shards aren't built here, so sort by what their IDs say
+	sortValue := func(id string) int {
+		r, number, _ := scheme.parseID(id)
+		return getShardSortValue(&Shard{Rarity: r, Number: number, scheme: scheme})
+	}
+	byShardOrder := func(a, b string) int {
+		return sortValue(a) - sortValue(b)
+	}
+	slices.SortFunc(migration.Structured, byShardOrder)
+	slices.SortFunc(migration.Migrated, byShardOrder)
+	slices.SortFunc(migration.NeedsReview, func(a, b EffectReview) int {
+		return byShardOrder(a.ID, b.ID)
+	})
+
+	if out == "" {
+		return migration, nil
+	}
+	return migration, writeShardConfig(config, out, format)
+}
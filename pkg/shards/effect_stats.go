@@ -0,0 +1,139 @@
+package shards
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	scalingLinear = "linear"
+	scalingFixed  = "fixed"
+)
+
+// EffectStat is one stat an attribute grants. Shard data can list these under "effects"; shards
+// that don't get them parsed from their effect description.
+type EffectStat struct {
+	Stat string `json:"stat"`
+	// "%" for percentage stats, empty for flat ones
+	Unit string `json:"unit,omitempty"`
+	// When the stat applies, like "during night" or "against spiders". Empty means always.
+	Condition string `json:"condition,omitempty"`
+	// The skill the stat belongs to, like "fishing" for fishing speed. Empty means any.
+	Skill string `json:"skill,omitempty"`
+	// "effect2" for stats that use effect2Max
+	Value string `json:"value,omitempty"`
+	// "linear" (the default) grows with level, "fixed" grants the whole amount from level 1
+	Scaling string `json:"scaling,omitempty"`
+	// Filled in from effectMax or effect2Max when processing, never set in shard data
+	Max float64 `json:"max,omitempty"`
+}
+
+// At returns the stat granted at the given attribute level
+func (st EffectStat) At(level int) float64 {
+	level = min(max(level, 0), MaxAttributeLevel)
+	if st.Scaling == scalingFixed && level > 0 {
+		return st.Max
+	}
+	return math.Round(st.Max*float64(level)/MaxAttributeLevel*1e4) / 1e4
+}
+
+// Only descriptions that read as "+X stat" can be turned into stats. Anything phrased around the
+// number ("Deal X% more damage to spiders") is left alone.
+var (
+	effectStatPattern = regexp.MustCompile(`^\+?\{\{(effect2?)\}\}(%?) ([a-z][a-z ,]*)$`)
+	// Words that start a condition rather than continue the stat name
+	effectConditionPattern = regexp.MustCompile(` (while|during|against|on|in|for|if|toward|when)( |$)`)
+	statListSeparator      = regexp.MustCompile(`,? and |, `)
+)
+
+// parseEffectStats reads stats out of an effect description. It returns false if any part of the
+// description doesn't fit, so a shard never ends up with half of its effect.
+func parseEffectStats(desc string, effectMax float64, effect2Max float64) ([]EffectStat, bool) {
+	// "+{{effect}} fig fortune and +{{effect}} mangrove fortune" grants two stats
+	parts := strings.Split(desc, " and +")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = "+" + parts[i]
+	}
+
+	stats := make([]EffectStat, 0, len(parts))
+	for _, part := range parts {
+		match := effectStatPattern.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, false
+		}
+		value, max := "", effectMax
+		if match[1] == "effect2" {
+			value, max = "effect2", effect2Max
+		}
+		text, condition := strings.TrimPrefix(match[3], "more "), ""
+		if loc := effectConditionPattern.FindStringIndex(text); loc != nil {
+			text, condition = text[:loc[0]], text[loc[0]+1:]
+		}
+
+		// "+{{effect}} mining fortune, farming fortune, and foraging fortune" grants each of them
+		names := []string{text}
+		if condition == "" && match[2] == "" {
+			names = statListSeparator.Split(text, -1)
+		}
+		for _, name := range names {
+			stats = append(stats, EffectStat{
+				Stat:      strings.TrimSpace(name),
+				Unit:      match[2],
+				Condition: condition,
+				Value:     value,
+				Max:       max,
+			})
+		}
+	}
+	return stats, true
+}
+
+// scopeEffectStats sets the skill of parsed stats named after one, like "mining speed"
+func scopeEffectStats(stats []EffectStat, skills []string) {
+	for i, stat := range stats {
+		skill, _, _ := strings.Cut(stat.Stat, " ")
+		if skill != "global" && slices.Contains(skills, skill) {
+			stats[i].Skill = skill
+		}
+	}
+}
+
+// effectStatsFor returns the stats of a shard. Listed effects win over the description, which
+// only counts if it can be parsed completely. The bool reports whether the shard has stats at all.
+func effectStatsFor(data shardConfigData, config *shardConfig) ([]EffectStat, bool, error) {
+	if len(data.Effects) == 0 {
+		stats, ok := parseEffectStats(data.EffectDescription, data.EffectMax, data.Effect2Max)
+		scopeEffectStats(stats, config.Skills)
+		return stats, ok, nil
+	}
+
+	stats := slices.Clone(data.Effects)
+	for i, stat := range stats {
+		switch {
+		case strings.TrimSpace(stat.Stat) == "":
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d has no stat", i+1)
+		case stat.Unit != "" && stat.Unit != "%":
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d has invalid unit %q", i+1, stat.Unit)
+		case stat.Skill != "" && !slices.Contains(config.Skills, stat.Skill):
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d has invalid skill %q", i+1, stat.Skill)
+		case stat.Scaling != "" && stat.Scaling != scalingLinear && stat.Scaling != scalingFixed:
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d has invalid scaling %q", i+1, stat.Scaling)
+		case stat.Max != 0:
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d sets max, which comes from effectMax or effect2Max", i+1)
+		}
+		switch stat.Value {
+		case "", "effect":
+			stats[i].Value, stats[i].Max = "", data.EffectMax
+		case "effect2":
+			stats[i].Max = data.Effect2Max
+		default:
+			return nil, false, errorOf(ErrInvalidShardData, "effect %d has invalid value %q", i+1, stat.Value)
+		}
+		if stat.Scaling == scalingLinear {
+			stats[i].Scaling = ""
+		}
+	}
+	return stats, true, nil
+}
@@ -0,0 +1,95 @@
+package shards
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseEffectStats(t *testing.T) {
+	tests := []struct {
+		desc     string
+		expected []EffectStat
+	}{
+		{"+{{effect}} health", []EffectStat{{Stat: "health", Max: 20}}},
+		{"+{{effect}} sweep during night", []EffectStat{{Stat: "sweep", Condition: "during night", Max: 20}}},
+		{"+{{effect}}% more pet exp", []EffectStat{{Stat: "pet exp", Unit: "%", Max: 20}}},
+		{"+{{effect}} fig fortune and +{{effect2}} mangrove fortune", []EffectStat{
+			{Stat: "fig fortune", Max: 20}, {Stat: "mangrove fortune", Value: "effect2", Max: 5},
+		}},
+		{"+{{effect}} mining fortune, farming fortune, and foraging fortune", []EffectStat{
+			{Stat: "mining fortune", Max: 20}, {Stat: "farming fortune", Max: 20}, {Stat: "foraging fortune", Max: 20},
+		}},
+		{"Deal {{effect}}% more damage to spiders", nil},
+	}
+	for _, tt := range tests {
+		stats, ok := parseEffectStats(tt.desc, 20, 5)
+		if ok != (tt.expected != nil) || (ok && !reflect.DeepEqual(stats, tt.expected)) {
+			t.Errorf("%q: expected %+v, got %+v (%v)", tt.desc, tt.expected, stats, ok)
+		}
+	}
+}
+
+func TestStructuredEffects(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	edited := config.Shards["U24"]
+	edited.Effects = []EffectStat{{Stat: "damage", Unit: "%", Condition: "against undead monsters", Skill: "combat"}}
+	config.Shards["U24"] = edited
+	edited = config.Shards["C1"]
+	edited.Effects = []EffectStat{{Stat: "magic find", Scaling: scalingFixed}}
+	config.Shards["C1"] = edited
+
+	shardData, err := processShardConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	if stats := shardData.Shards["U24"].Stats; len(stats) != 1 || stats[0].Max != config.Shards["U24"].EffectMax {
+		t.Errorf("Listed effects should be used for U24, got %+v", stats)
+	}
+	if stats := shardData.Shards["C1"].Stats; len(stats) != 1 || stats[0].Stat != "magic find" || stats[0].At(1) != stats[0].Max {
+		t.Errorf("Listed effects should win over the description for C1, got %+v", stats)
+	}
+	if len(shardData.StatShards["damage"]) != 1 || len(shardData.StatShards["strength"]) == 0 {
+		t.Errorf("Unexpected stat groups: %v", shardData.StatShards)
+	}
+
+	edited.Effects = []EffectStat{{Stat: "magic find", Max: 5}}
+	config.Shards["C1"] = edited
+	if _, err := processShardConfig(config); !errors.Is(err, ErrInvalidShardData) {
+		t.Errorf("Expected effects that set max to be rejected, got %v", err)
+	}
+}
+
+func TestMigrateEffects(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "shards.json")
+	migration, err := MigrateEffects(testShardDataLocation, out, FormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to migrate effects: %v", err)
+	}
+	if len(migration.Migrated) == 0 || len(migration.NeedsReview) == 0 {
+		t.Errorf("Expected some shards to parse and some to need review, got %+v", migration)
+	}
+
+	before, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	after, err := ProcessShards(out)
+	if err != nil {
+		t.Fatalf("Failed to process migrated shard data: %v", err)
+	}
+	for id, s := range before.Shards {
+		if !reflect.DeepEqual(s.Stats, after.Shards[id].Stats) {
+			t.Errorf("%s: stats changed from %+v to %+v", id, s.Stats, after.Shards[id].Stats)
+		}
+	}
+
+	again, err := MigrateEffects(out, "", "")
+	if err != nil || len(again.Structured) != len(migration.Migrated) || len(again.Migrated) != 0 {
+		t.Errorf("Migrating twice should find every parsed shard structured, got %+v, %v", again, err)
+	}
+}
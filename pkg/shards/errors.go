@@ -0,0 +1,51 @@
+package shards
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidShardData is returned for shard data that can't be read or fails validation
+	ErrInvalidShardData = errors.New("invalid shard data")
+	// ErrInvalidRequirement is returned for special fuse requirements that can never be evaluated
+	ErrInvalidRequirement = errors.New("invalid special fuse requirement")
+	// ErrUnknownShard is returned when an ID, name or alias doesn't refer to any shard
+	ErrUnknownShard = errors.New("unknown shard")
+	// ErrAmbiguousShard is returned when a query matches several shards equally well
+	ErrAmbiguousShard = errors.New("ambiguous shard")
+)
+
+// ShardError reports which shard in the config failed to process
+type ShardError struct {
+	ID  string
+	Op  string
+	Err error
+}
+
+func (e *ShardError) Error() string {
+	return fmt.Sprintf("error %s for shard ID %s: %v", e.Op, e.ID, e.Err)
+}
+
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
+// kindError keeps a readable message while still matching one of the sentinel errors with errors.Is
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// errorOf formats like fmt.Errorf, so %w still wraps its cause, and also matches kind
+func errorOf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
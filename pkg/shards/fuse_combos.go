@@ -1,17 +1,23 @@
 package shards
 
 import (
+	"cmp"
+	"math"
+	"runtime"
 	"slices"
+	"sync"
 )
 
 type specialFuseOption struct {
 	target string
 	option *specialFuse
+	req1   shardBitset
+	req2   shardBitset
 }
 
-func getAllSpecialFuseOptions(shards map[string]*shard) []*specialFuseOption {
+func getAllSpecialFuseOptions(shards map[string]*Shard, matcher *requirementMatcher) []*specialFuseOption {
 	fuseOptions := make([]*specialFuseOption, 0, 100)
-	for _, s := range shards {
+	for _, s := range getSortedShards(shards) {
 		if len(s.SpecialFuses) == 0 {
 			continue
 		}
@@ -19,127 +25,177 @@ func getAllSpecialFuseOptions(shards map[string]*shard) []*specialFuseOption {
 			option := specialFuseOption{
 				target: s.ID,
 				option: &sf,
+				req1:   matcher.compile(&sf.Requirement1),
+				req2:   matcher.compile(&sf.Requirement2),
 			}
 			fuseOptions = append(fuseOptions, &option)
 		}
 	}
 
-	slices.SortFunc(fuseOptions, func(a, b *specialFuseOption) int {
-		return getFusePriority(b, shards) - getFusePriority(a, shards)
+	// Stable so several fuses into the same shard keep the order they're declared in
+	slices.SortStableFunc(fuseOptions, func(a, b *specialFuseOption) int {
+		return cmp.Or(getFusePriority(b, shards)-getFusePriority(a, shards), cmp.Compare(a.target, b.target))
 	})
 
 	return fuseOptions
 }
 
-func getFusePriority(opt *specialFuseOption, shards map[string]*shard) int {
+func getFusePriority(opt *specialFuseOption, shards map[string]*Shard) int {
 	shard := shards[opt.target]
 	// prioritize high rarity, then lower number
-	sortNum := (1000 - shard.Number) + rarityValue(shard.Rarity)*10000
+	sortNum := (1000 - shard.Number) + shard.scheme.rank(shard.Rarity)*10000
 	return sortNum
 }
 
-func addFuseCombos(shards map[string]*shard, cfg *shardConfig) {
-	sortedShards := getSortedShards(shards)
-	allSpecialFuses := getAllSpecialFuseOptions(shards)
-	// We need to loop over the full list twice because order does matter, and fusion with self is possible
-	for _, s1 := range sortedShards {
-		for _, s2 := range sortedShards {
-			results := make([]fuseResult, 0, 10)
-
-			// Priority 1: chameleon
-			if s1.ID == chameleonId {
-				for _, target := range s2.ChameleonTargets {
-					results = append(results, fuseResult{
-						Type:       "chameleon",
-						ID:         target,
-						Multiplier: 1,
-					})
-				}
-			} else if s2.ID == chameleonId {
-				for _, target := range s1.ChameleonTargets {
-					results = append(results, fuseResult{
-						Type:       "chameleon",
-						ID:         target,
-						Multiplier: 1,
-					})
-				}
-			}
+type fuseCombiner struct {
+	cfg             *shardConfig
+	wildcards       []wildcardConfig
+	matcher         *requirementMatcher
+	allSpecialFuses []*specialFuseOption
+}
 
-			// Priority 2: basic fuses
-			useS1 := s1.BasicFuseTarget != ""
-			useS2 := s2.BasicFuseTarget != ""
-
-			if s1.Category == s2.Category {
-				s1Rarity := rarityValue(s1.Rarity)
-				s2Rarity := rarityValue(s2.Rarity)
-				if s1Rarity > s2Rarity {
-					useS2 = false
-				} else {
-					// If they are equal, it uses the 2nd one, which causes order to matter
-					useS1 = false
-				}
-			}
+// The combiner snapshots shard attributes, so it must be rebuilt after any shard changes
+func newFuseCombiner(shards map[string]*Shard, cfg *shardConfig) *fuseCombiner {
+	matcher := newRequirementMatcher(getSortedShards(shards))
+	return &fuseCombiner{
+		cfg:             cfg,
+		wildcards:       cfg.wildcards(),
+		matcher:         matcher,
+		allSpecialFuses: getAllSpecialFuseOptions(shards, matcher),
+	}
+}
 
-			if useS1 {
-				results = append(results, fuseResult{
-					Type:       "basic",
-					ID:         s1.BasicFuseTarget,
-					Multiplier: 1,
-				})
-			}
-			if useS2 {
-				results = append(results, fuseResult{
-					Type:       "basic",
-					ID:         s2.BasicFuseTarget,
-					Multiplier: 1,
-				})
-			}
+func addFuseCombos(shards map[string]*Shard, cfg *shardConfig, workers int) {
+	combiner := newFuseCombiner(shards, cfg)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 {
+		for _, s1 := range combiner.matcher.shards {
+			combiner.addRow(s1)
+		}
+		return
+	}
 
-			// Priority 3: special fuses
-			// Need to figure out how these are prioritized
-			for _, opt := range allSpecialFuses {
-				dir1 := meetsSpecialFuseRequirement(s1, &opt.option.Requirement1) && meetsSpecialFuseRequirement(s2, &opt.option.Requirement2)
-				dir2 := meetsSpecialFuseRequirement(s2, &opt.option.Requirement1) && meetsSpecialFuseRequirement(s1, &opt.option.Requirement2)
-				if dir1 || dir2 {
-					mult := 1
-					if opt.option.IsBoosted {
-						mult = cfg.SpecialFuseMultiplier
-					}
-					results = append(results, fuseResult{
-						Type:       "special",
-						ID:         opt.target,
-						Multiplier: mult,
-					})
-				}
+	// Workers take whole rows. A row only writes to its own shard's FuseCombinations and the
+	// combiner is read-only, so no locking is needed and the result doesn't depend on scheduling.
+	rows := make(chan *Shard)
+	var wg sync.WaitGroup
+	for range min(workers, len(combiner.matcher.shards)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s1 := range rows {
+				combiner.addRow(s1)
 			}
+		}()
+	}
+	for _, s1 := range combiner.matcher.shards {
+		rows <- s1
+	}
+	close(rows)
+	wg.Wait()
+}
 
-			if len(results) == 0 {
-				continue
-			}
+// addRow fuses s1 with every shard, itself included. Order matters, so s1 is always first here
+// and comes second in the other rows.
+func (c *fuseCombiner) addRow(s1 *Shard) {
+	for _, s2 := range c.matcher.shards {
+		if combo, ok := c.combine(s1, s2); ok {
+			s1.FuseCombinations[s2.ID] = combo
+		}
+	}
+}
 
-			s1Cost := cfg.FamilyFuseCost["default"]
-			s2Cost := cfg.FamilyFuseCost["default"]
-			for family, cost := range cfg.FamilyFuseCost {
-				if cost < s1Cost && s1.Families[family] {
-					s1Cost = cfg.FamilyFuseCost[family]
-				}
-				if cost < s2Cost && s2.Families[family] {
-					s2Cost = cfg.FamilyFuseCost[family]
-				}
-			}
+func (c *fuseCombiner) combine(s1 *Shard, s2 *Shard) (FuseCombination, bool) {
+	cfg := c.cfg
 
-			if len(results) > 3 {
-				//fmt.Printf("Shard1: %s, Shard2: %s, Results: %v\n", s1.ID, s2.ID, results)
-				results = results[:3]
-			}
+	results := make([]FuseResult, 0, 10)
+
+	// Wildcards like the chameleon usually come first
+	results = c.appendWildcardResults(results, s1, s2, math.MinInt, basicFusePriority)
+
+	// Then basic fuses
+	useS1 := s1.BasicFuseTarget != ""
+	useS2 := s2.BasicFuseTarget != ""
+
+	if s1.Category == s2.Category {
+		s1Rarity := s1.scheme.rank(s1.Rarity)
+		s2Rarity := s2.scheme.rank(s2.Rarity)
+		if s1Rarity > s2Rarity {
+			useS2 = false
+		} else {
+			// If they are equal, it uses the 2nd one, which causes order to matter
+			useS1 = false
+		}
+	}
+
+	if useS1 {
+		results = append(results, FuseResult{
+			Type:       "basic",
+			ID:         s1.BasicFuseTarget,
+			Multiplier: 1,
+		})
+	}
+	if useS2 {
+		results = append(results, FuseResult{
+			Type:       "basic",
+			ID:         s2.BasicFuseTarget,
+			Multiplier: 1,
+		})
+	}
+
+	results = c.appendWildcardResults(results, s1, s2, basicFusePriority, specialFusePriority)
+
+	// Then special fuses
+	results = c.appendSpecialResults(results, s1, s2)
+	results = c.appendWildcardResults(results, s1, s2, specialFusePriority, math.MaxInt)
+
+	if len(results) == 0 {
+		return FuseCombination{}, false
+	}
+
+	s1Cost := cfg.FamilyFuseCost["default"]
+	s2Cost := cfg.FamilyFuseCost["default"]
+	for family, cost := range cfg.FamilyFuseCost {
+		if cost < s1Cost && s1.Families[family] {
+			s1Cost = cfg.FamilyFuseCost[family]
+		}
+		if cost < s2Cost && s2.Families[family] {
+			s2Cost = cfg.FamilyFuseCost[family]
+		}
+	}
+
+	if len(results) > 3 {
+		//fmt.Printf("Shard1: %s, Shard2: %s, Results: %v\n", s1.ID, s2.ID, results)
+		results = results[:3]
+	}
+
+	return FuseCombination{
+		Shard1:  s1.ID,
+		Cost1:   s1Cost,
+		Shard2:  s2.ID,
+		Cost2:   s2Cost,
+		Results: results,
+	}, true
+}
 
-			s1.FuseCombinations[s2.ID] = fuseCombination{
-				Shard1:  s1.ID,
-				Cost1:   s1Cost,
-				Shard2:  s2.ID,
-				Cost2:   s2Cost,
-				Results: results,
+func (c *fuseCombiner) appendSpecialResults(results []FuseResult, s1 *Shard, s2 *Shard) []FuseResult {
+	i1, i2 := c.matcher.index[s1.ID], c.matcher.index[s2.ID]
+	for _, opt := range c.allSpecialFuses {
+		dir1 := opt.req1.has(i1) && opt.req2.has(i2)
+		dir2 := opt.req1.has(i2) && opt.req2.has(i1)
+		if dir1 || dir2 {
+			mult := 1
+			if opt.option.IsBoosted {
+				mult = c.cfg.SpecialFuseMultiplier
 			}
+			results = append(results, FuseResult{
+				Type:       "special",
+				ID:         opt.target,
+				Multiplier: mult,
+			})
 		}
 	}
+	return results
 }
@@ -0,0 +1,85 @@
+package shards
+
+import (
+	"cmp"
+	"slices"
+)
+
+type FusionPath struct {
+	Target        string  `json:"target"`
+	Type          string  `json:"type"`
+	Shard1        string  `json:"shard1"`
+	Cost1         int     `json:"cost1"`
+	Shard2        string  `json:"shard2"`
+	Cost2         int     `json:"cost2"`
+	Multiplier    int     `json:"multiplier"`
+	PricePerShard float64 `json:"pricePerShard"`
+}
+
+// FusionPaths lists every priced way to fuse the target, cheapest first.
+// Prices are keyed by bazaar ID, the same as shard_prices.json.
+func (d *ProcessedShardData) FusionPaths(target string, prices map[string]float64) []FusionPath {
+	paths := make([]FusionPath, 0)
+	d.eachFusionPath(prices, func(p FusionPath) {
+		if p.Target == target {
+			paths = append(paths, p)
+		}
+	})
+	slices.SortFunc(paths, compareFusionPaths)
+	return paths
+}
+
+// CheapestFusions returns the cheapest priced fusion for every shard that can be fused.
+func (d *ProcessedShardData) CheapestFusions(prices map[string]float64) map[string]FusionPath {
+	cheapest := make(map[string]FusionPath)
+	d.eachFusionPath(prices, func(p FusionPath) {
+		if best, exists := cheapest[p.Target]; !exists || compareFusionPaths(p, best) < 0 {
+			cheapest[p.Target] = p
+		}
+	})
+	return cheapest
+}
+
+func (d *ProcessedShardData) eachFusionPath(prices map[string]float64, fn func(FusionPath)) {
+	for _, s1 := range d.Shards {
+		price1, ok := prices[s1.BazaarId]
+		if !ok || price1 <= 0 {
+			continue
+		}
+		for id2, combo := range s1.FuseCombinations {
+			s2, exists := d.Shards[id2]
+			if !exists {
+				continue
+			}
+			price2, ok := prices[s2.BazaarId]
+			if !ok || price2 <= 0 {
+				continue
+			}
+			for _, result := range combo.Results {
+				// Fusing a shard into itself is never a useful way to acquire it
+				if result.ID == s1.ID || result.ID == s2.ID {
+					continue
+				}
+				fn(FusionPath{
+					Target:        result.ID,
+					Type:          result.Type,
+					Shard1:        s1.ID,
+					Cost1:         combo.Cost1,
+					Shard2:        s2.ID,
+					Cost2:         combo.Cost2,
+					Multiplier:    result.Multiplier,
+					PricePerShard: (price1*float64(combo.Cost1) + price2*float64(combo.Cost2)) / float64(result.Multiplier),
+				})
+			}
+		}
+	}
+}
+
+func compareFusionPaths(a, b FusionPath) int {
+	return cmp.Or(
+		cmp.Compare(a.PricePerShard, b.PricePerShard),
+		cmp.Compare(a.Shard1, b.Shard1),
+		cmp.Compare(a.Shard2, b.Shard2),
+		cmp.Compare(a.Type, b.Type),
+	)
+}
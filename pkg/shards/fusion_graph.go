@@ -0,0 +1,156 @@
+package shards
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// FusionGraph is the fusion data viewed as a directed graph: every input shard of a fusion
+// pair has an edge to each shard the pair can produce. Pairs that give the same input, target,
+// type and multiplier are collapsed into one edge, counted in Pairs.
+type FusionGraph struct {
+	Nodes []*Shard
+	Edges []FusionEdge
+}
+
+type FusionEdge struct {
+	Source     string
+	Target     string
+	Type       string
+	Multiplier int
+	Pairs      int
+}
+
+// GraphFilter restricts a graph to the subgraph induced by the matching shards. Empty lists
+// match everything; within a list any value matches.
+type GraphFilter struct {
+	Families   []string
+	Categories []string
+	Rarities   []string
+	Types      []string
+}
+
+// validate rejects values no shard or fusion has, which would otherwise give an empty graph
+func (f *GraphFilter) validate(d *ProcessedShardData) error {
+	families := make(map[string]bool)
+	types := make(map[string]bool)
+	for _, s := range d.Shards {
+		maps.Copy(families, s.Families)
+		if len(f.Types) > 0 {
+			for _, combo := range s.FuseCombinations {
+				for _, result := range combo.Results {
+					types[result.Type] = true
+				}
+			}
+		}
+	}
+	scheme := d.scheme.orDefault()
+	rarities := make([]string, 0, len(scheme.rarities))
+	for _, def := range scheme.rarities {
+		rarities = append(rarities, string(def.ID))
+	}
+	categories := make([]string, 0, len(scheme.categories))
+	for _, def := range scheme.categories {
+		categories = append(categories, string(def.ID))
+	}
+
+	for _, check := range []struct {
+		name   string
+		values []string
+		known  []string
+	}{
+		{"family", f.Families, slices.Sorted(maps.Keys(families))},
+		{"category", f.Categories, categories},
+		{"rarity", f.Rarities, rarities},
+		{"fuse type", f.Types, slices.Sorted(maps.Keys(types))},
+	} {
+		for _, value := range check.values {
+			if !slices.Contains(check.known, value) {
+				return fmt.Errorf("unknown %s %q, expected one of %s", check.name, value, strings.Join(check.known, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func (f *GraphFilter) includesShard(s *Shard) bool {
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, string(s.Category)) {
+		return false
+	}
+	if len(f.Rarities) > 0 && !slices.Contains(f.Rarities, string(s.Rarity)) {
+		return false
+	}
+	if len(f.Families) > 0 && !slices.ContainsFunc(f.Families, func(family string) bool { return s.Families[family] }) {
+		return false
+	}
+	return true
+}
+
+func (f *GraphFilter) includesType(fuseType string) bool {
+	return len(f.Types) == 0 || slices.Contains(f.Types, fuseType)
+}
+
+func (d *ProcessedShardData) FusionGraph(filter GraphFilter) (*FusionGraph, error) {
+	if err := filter.validate(d); err != nil {
+		return nil, err
+	}
+
+	type edgeKey struct {
+		source, target, fuseType string
+		multiplier               int
+	}
+
+	included := make(map[string]bool, len(d.Shards))
+	nodes := make([]*Shard, 0, len(d.Shards))
+	for _, s := range getSortedShards(d.Shards) {
+		if filter.includesShard(s) {
+			included[s.ID] = true
+			nodes = append(nodes, s)
+		}
+	}
+
+	pairs := make(map[edgeKey]int)
+	for _, s1 := range nodes {
+		for id2, combo := range s1.FuseCombinations {
+			if !included[id2] {
+				continue
+			}
+			for _, result := range combo.Results {
+				if !included[result.ID] || !filter.includesType(result.Type) {
+					continue
+				}
+				pairs[edgeKey{s1.ID, result.ID, result.Type, result.Multiplier}]++
+				if id2 != s1.ID {
+					pairs[edgeKey{id2, result.ID, result.Type, result.Multiplier}]++
+				}
+			}
+		}
+	}
+
+	edges := make([]FusionEdge, 0, len(pairs))
+	for key, count := range pairs {
+		edges = append(edges, FusionEdge{
+			Source:     key.source,
+			Target:     key.target,
+			Type:       key.fuseType,
+			Multiplier: key.multiplier,
+			Pairs:      count,
+		})
+	}
+	slices.SortFunc(edges, func(a, b FusionEdge) int {
+		return cmp.Or(
+			getShardSortValue(d.Shards[a.Source])-getShardSortValue(d.Shards[b.Source]),
+			getShardSortValue(d.Shards[a.Target])-getShardSortValue(d.Shards[b.Target]),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Multiplier, b.Multiplier),
+		)
+	})
+
+	return &FusionGraph{
+		Nodes: nodes,
+		Edges: edges,
+	}, nil
+}
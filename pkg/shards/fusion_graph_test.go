@@ -0,0 +1,132 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"testing"
+)
+
+// testGraphData is three shards small enough to count pairs by hand. C1 and C2 give U1 through
+// the chameleon or a basic fuse in either order, and C1 with itself gives a boosted special fuse.
+func testGraphData() *ProcessedShardData {
+	shards := map[string]*Shard{
+		"C1": {ID: "C1", Name: "Grove", Rarity: rarityCommon, Number: 1, Category: categoryForest, Families: map[string]bool{"Elemental": true}},
+		"C2": {ID: "C2", Name: "Mossybit", Rarity: rarityCommon, Number: 2, Category: categoryWater, Families: map[string]bool{}},
+		"U1": {ID: "U1", Name: "Birries", Rarity: rarityUncommon, Number: 1, Category: categoryForest, Families: map[string]bool{}},
+	}
+	shards["C1"].FuseCombinations = map[string]FuseCombination{
+		"C2": {Shard1: "C1", Shard2: "C2", Results: []FuseResult{{"chameleon", "U1", 1}, {"basic", "U1", 1}}},
+		"C1": {Shard1: "C1", Shard2: "C1", Results: []FuseResult{{"special", "U1", 2}}},
+	}
+	shards["C2"].FuseCombinations = map[string]FuseCombination{
+		"C1": {Shard1: "C2", Shard2: "C1", Results: []FuseResult{{"chameleon", "U1", 1}, {"basic", "U1", 1}}},
+	}
+	shards["U1"].FuseCombinations = map[string]FuseCombination{}
+	return &ProcessedShardData{Shards: shards}
+}
+
+func TestFusionGraph(t *testing.T) {
+	data := testGraphData()
+	tests := []struct {
+		name   string
+		filter GraphFilter
+		nodes  []string
+		edges  []FusionEdge
+	}{
+		{"everything", GraphFilter{}, []string{"C1", "C2", "U1"}, []FusionEdge{
+			{"C1", "U1", "basic", 1, 2},
+			{"C1", "U1", "chameleon", 1, 2},
+			// A shard fused with itself is one pair, not two
+			{"C1", "U1", "special", 2, 1},
+			{"C2", "U1", "basic", 1, 2},
+			{"C2", "U1", "chameleon", 1, 2},
+		}},
+		{"category", GraphFilter{Categories: []string{"forest"}}, []string{"C1", "U1"}, []FusionEdge{
+			{"C1", "U1", "special", 2, 1},
+		}},
+		{"type", GraphFilter{Types: []string{"basic"}}, []string{"C1", "C2", "U1"}, []FusionEdge{
+			{"C1", "U1", "basic", 1, 2},
+			{"C2", "U1", "basic", 1, 2},
+		}},
+		{"rarity", GraphFilter{Rarities: []string{"common"}}, []string{"C1", "C2"}, []FusionEdge{}},
+		{"family", GraphFilter{Families: []string{"Elemental"}}, []string{"C1"}, []FusionEdge{}},
+	}
+	for _, tt := range tests {
+		graph, err := data.FusionGraph(tt.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		nodes := make([]string, 0, len(graph.Nodes))
+		for _, s := range graph.Nodes {
+			nodes = append(nodes, s.ID)
+		}
+		if !slices.Equal(nodes, tt.nodes) || !slices.Equal(graph.Edges, tt.edges) {
+			t.Errorf("%s: expected %v with %v, got %v with %v", tt.name, tt.nodes, tt.edges, nodes, graph.Edges)
+		}
+	}
+
+	for _, filter := range []GraphFilter{
+		{Rarities: []string{"bogus"}},
+		{Categories: []string{"Forest"}},
+		{Families: []string{"Reptile"}},
+		{Types: []string{"mimic"}},
+	} {
+		if _, err := data.FusionGraph(filter); err == nil {
+			t.Errorf("Expected %+v to be rejected", filter)
+		}
+	}
+}
+
+func TestGraphWriters(t *testing.T) {
+	graph, err := testGraphData().FusionGraph(GraphFilter{Categories: []string{"forest"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dot bytes.Buffer
+	if err := graph.WriteDOT(&dot); err != nil {
+		t.Fatalf("Failed to write DOT: %v", err)
+	}
+	expectedDOT := `digraph fusions {
+  node [shape=box, style=filled];
+  "C1" [label="Grove\nC1", fillcolor="#ffffff", category="forest"];
+  "U1" [label="Birries\nU1", fillcolor="#55ff55", category="forest"];
+  "C1" -> "U1" [color="#1f5fbf", penwidth=2, label="special x2", weight=1];
+}
+`
+	if dot.String() != expectedDOT {
+		t.Errorf("Unexpected DOT:\n%s", dot.String())
+	}
+
+	var csv bytes.Buffer
+	if err := graph.WriteCSV(&csv); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	if expected := "source,target,type,multiplier,pairs\nC1,U1,special,2,1\n"; csv.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s", csv.String())
+	}
+
+	var gexf bytes.Buffer
+	if err := graph.WriteGEXF(&gexf); err != nil {
+		t.Fatalf("Failed to write GEXF: %v", err)
+	}
+	var doc struct {
+		Nodes []struct {
+			ID    string    `xml:"id,attr"`
+			Label string    `xml:"label,attr"`
+			Color gexfColor `xml:"color"`
+		} `xml:"graph>nodes>node"`
+		Edges []gexfEdge `xml:"graph>edges>edge"`
+	}
+	if err := xml.Unmarshal(gexf.Bytes(), &doc); err != nil {
+		t.Fatalf("GEXF doesn't parse: %v", err)
+	}
+	if len(doc.Nodes) != 2 || doc.Nodes[1].Label != "Birries" || doc.Nodes[1].Color.G != 0xff || doc.Nodes[1].Color.R != 0x55 {
+		t.Errorf("Unexpected GEXF nodes: %+v", doc.Nodes)
+	}
+	if len(doc.Edges) != 1 || doc.Edges[0].Source != "C1" || doc.Edges[0].Weight != 1 || doc.Edges[0].Label != "special x2" {
+		t.Errorf("Unexpected GEXF edges: %+v", doc.Edges)
+	}
+}
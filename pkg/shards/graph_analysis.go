@@ -0,0 +1,174 @@
+package shards
+
+import (
+	"cmp"
+	"slices"
+)
+
+const fusionOnlySourceType = "fusionOnly"
+
+type FusionReport struct {
+	// Shards that can only be obtained by fusion, and how
+	FusionOnly []FusionOnlyInfo `json:"fusionOnly"`
+	// Fewest fusion steps from directly obtainable shards. Obtainable shards are 0;
+	// shards that cannot be reached are left out.
+	MinSteps map[string]int `json:"minSteps"`
+	// Input shards ordered by how many distinct targets they can produce
+	Hubs []HubInfo `json:"hubs"`
+	// Targets with exactly one producing pair
+	Fragile []FragileTarget `json:"fragile"`
+}
+
+type FusionOnlyInfo struct {
+	ID         string `json:"id"`
+	Producible bool   `json:"producible"`
+	// Every shard that appears in a pair producing this one
+	Inputs []string `json:"inputs"`
+	// Source types of the directly obtainable inputs, i.e. where the raw materials come from
+	InputSourceTypes []string `json:"inputSourceTypes"`
+}
+
+type HubInfo struct {
+	ID      string `json:"id"`
+	Targets int    `json:"targets"`
+}
+
+type FragileTarget struct {
+	ID     string `json:"id"`
+	Shard1 string `json:"shard1"`
+	Shard2 string `json:"shard2"`
+}
+
+type shardPair struct {
+	shard1, shard2 string
+}
+
+// producingPairs maps each target to the unordered pairs that can produce it. Pairs that
+// produce one of their own inputs are not counted for that input.
+func (d *ProcessedShardData) producingPairs() map[string]map[shardPair]bool {
+	pairs := make(map[string]map[shardPair]bool)
+	for _, s1 := range d.Shards {
+		for id2, combo := range s1.FuseCombinations {
+			pair := shardPair{min(s1.ID, id2), max(s1.ID, id2)}
+			for _, result := range combo.Results {
+				if result.ID == s1.ID || result.ID == id2 {
+					continue
+				}
+				if pairs[result.ID] == nil {
+					pairs[result.ID] = make(map[shardPair]bool)
+				}
+				pairs[result.ID][pair] = true
+			}
+		}
+	}
+	return pairs
+}
+
+func isDirectlyObtainable(s *Shard) bool {
+	return slices.ContainsFunc(s.Sources, func(src source) bool {
+		return src.SourceType != fusionOnlySourceType
+	})
+}
+
+func (d *ProcessedShardData) FusionReport() *FusionReport {
+	pairs := d.producingPairs()
+	sorted := getSortedShards(d.Shards)
+	byShardOrder := func(a, b string) int {
+		return getShardSortValue(d.Shards[a]) - getShardSortValue(d.Shards[b])
+	}
+
+	// Relax until nothing improves. A pair can fire once both inputs are reachable, and the
+	// result is one step past the slower input.
+	steps := make(map[string]int, len(d.Shards))
+	for _, s := range sorted {
+		if isDirectlyObtainable(s) {
+			steps[s.ID] = 0
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for target, targetPairs := range pairs {
+			for pair := range targetPairs {
+				step1, ok1 := steps[pair.shard1]
+				step2, ok2 := steps[pair.shard2]
+				if !ok1 || !ok2 {
+					continue
+				}
+				candidate := max(step1, step2) + 1
+				if current, ok := steps[target]; !ok || candidate < current {
+					steps[target] = candidate
+					changed = true
+				}
+			}
+		}
+	}
+
+	fusionOnly := make([]FusionOnlyInfo, 0)
+	for _, id := range d.SourceTypeShards[fusionOnlySourceType] {
+		inputSet := make(map[string]bool)
+		for pair := range pairs[id] {
+			inputSet[pair.shard1] = true
+			inputSet[pair.shard2] = true
+		}
+		inputs := make([]string, 0, len(inputSet))
+		sourceTypeSet := make(map[string]bool)
+		for input := range inputSet {
+			inputs = append(inputs, input)
+			for _, src := range d.Shards[input].Sources {
+				if src.SourceType != fusionOnlySourceType {
+					sourceTypeSet[src.SourceType] = true
+				}
+			}
+		}
+		slices.SortFunc(inputs, byShardOrder)
+		sourceTypes := make([]string, 0, len(sourceTypeSet))
+		for sourceType := range sourceTypeSet {
+			sourceTypes = append(sourceTypes, sourceType)
+		}
+		slices.Sort(sourceTypes)
+
+		_, producible := steps[id]
+		fusionOnly = append(fusionOnly, FusionOnlyInfo{
+			ID:               id,
+			Producible:       producible,
+			Inputs:           inputs,
+			InputSourceTypes: sourceTypes,
+		})
+	}
+
+	targetsByInput := make(map[string]map[string]bool)
+	for target, targetPairs := range pairs {
+		for pair := range targetPairs {
+			for _, input := range []string{pair.shard1, pair.shard2} {
+				if targetsByInput[input] == nil {
+					targetsByInput[input] = make(map[string]bool)
+				}
+				targetsByInput[input][target] = true
+			}
+		}
+	}
+	hubs := make([]HubInfo, 0, len(targetsByInput))
+	for input, targets := range targetsByInput {
+		hubs = append(hubs, HubInfo{ID: input, Targets: len(targets)})
+	}
+	slices.SortFunc(hubs, func(a, b HubInfo) int {
+		return cmp.Or(b.Targets-a.Targets, byShardOrder(a.ID, b.ID))
+	})
+
+	fragile := make([]FragileTarget, 0)
+	for _, s := range sorted {
+		if len(pairs[s.ID]) != 1 {
+			continue
+		}
+		for pair := range pairs[s.ID] {
+			fragile = append(fragile, FragileTarget{ID: s.ID, Shard1: pair.shard1, Shard2: pair.shard2})
+		}
+	}
+
+	return &FusionReport{
+		FusionOnly: fusionOnly,
+		MinSteps:   steps,
+		Hubs:       hubs,
+		Fragile:    fragile,
+	}
+}
@@ -0,0 +1,51 @@
+package shards
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFusionReport(t *testing.T) {
+	fishing := []source{{SourceType: "fishing", SourceDesc: "Fishing"}}
+	fusionOnly := []source{{SourceType: fusionOnlySourceType, SourceDesc: "Fusion Only"}}
+	basic := func(id string) FuseCombination {
+		return FuseCombination{Results: []FuseResult{{"basic", id, 1}}}
+	}
+	// U1 is one step from the raw shards and U2 two; U3 only comes from a pair it's part of
+	data := &ProcessedShardData{
+		Shards: map[string]*Shard{
+			"C1": {ID: "C1", Rarity: rarityCommon, Number: 1, Sources: fishing, FuseCombinations: map[string]FuseCombination{
+				"C2": basic("U1"), "U1": basic("U2"), "U3": basic("U3"),
+			}},
+			"C2": {ID: "C2", Rarity: rarityCommon, Number: 2, Sources: fishing, FuseCombinations: map[string]FuseCombination{
+				"C2": basic("U1"),
+			}},
+			"U1": {ID: "U1", Rarity: rarityUncommon, Number: 1, Sources: fusionOnly},
+			"U2": {ID: "U2", Rarity: rarityUncommon, Number: 2, Sources: fusionOnly},
+			"U3": {ID: "U3", Rarity: rarityUncommon, Number: 3, Sources: fusionOnly},
+		},
+		SourceTypeShards: map[string][]string{fusionOnlySourceType: {"U1", "U2", "U3"}},
+	}
+	report := data.FusionReport()
+
+	if want := map[string]int{"C1": 0, "C2": 0, "U1": 1, "U2": 2}; !maps.Equal(report.MinSteps, want) {
+		t.Errorf("Expected steps %v, got %v", want, report.MinSteps)
+	}
+	wantFusionOnly := []FusionOnlyInfo{
+		{ID: "U1", Producible: true, Inputs: []string{"C1", "C2"}, InputSourceTypes: []string{"fishing"}},
+		{ID: "U2", Producible: true, Inputs: []string{"C1", "U1"}, InputSourceTypes: []string{"fishing"}},
+		{ID: "U3", Producible: false, Inputs: []string{}, InputSourceTypes: []string{}},
+	}
+	if !reflect.DeepEqual(report.FusionOnly, wantFusionOnly) {
+		t.Errorf("Expected fusion-only shards %+v, got %+v", wantFusionOnly, report.FusionOnly)
+	}
+	if want := []HubInfo{{"C1", 2}, {"C2", 1}, {"U1", 1}}; !slices.Equal(report.Hubs, want) {
+		t.Errorf("Expected hubs %v, got %v", want, report.Hubs)
+	}
+	// U1 also comes from C2 with itself, so only U2 hangs on one pair
+	if want := []FragileTarget{{"U2", "C1", "U1"}}; !slices.Equal(report.Fragile, want) {
+		t.Errorf("Expected fragile targets %v, got %v", want, report.Fragile)
+	}
+}
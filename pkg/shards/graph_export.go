@@ -0,0 +1,190 @@
+package shards
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Wildcards other than the chameleon are drawn like it
+var fuseTypeColors = map[string]string{
+	"chameleon": "#2e8b57",
+	"basic":     "#888888",
+	"special":   "#1f5fbf",
+}
+
+func fuseTypeColor(fuseType string) string {
+	if color, ok := fuseTypeColors[fuseType]; ok {
+		return color
+	}
+	return fuseTypeColors["chameleon"]
+}
+
+func (g *FusionGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph fusions {")
+	fmt.Fprintln(bw, "  node [shape=box, style=filled];")
+	for _, s := range g.Nodes {
+		fmt.Fprintf(bw, "  %q [label=%q, fillcolor=%q, category=%q];\n",
+			s.ID, s.Name+"\n"+s.ID, s.scheme.rarityDef(s.Rarity).Color, string(s.Category))
+	}
+	for _, e := range g.Edges {
+		// Boosted special fusions are drawn thicker so they stand out
+		fmt.Fprintf(bw, "  %q -> %q [color=%q, penwidth=%d, label=%q, weight=%d];\n",
+			e.Source, e.Target, fuseTypeColor(e.Type), e.Multiplier, edgeLabel(e), e.Pairs)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func edgeLabel(e FusionEdge) string {
+	if e.Multiplier > 1 {
+		return e.Type + " x" + strconv.Itoa(e.Multiplier)
+	}
+	return e.Type
+}
+
+func (g *FusionGraph) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"source", "target", "type", "multiplier", "pairs"}); err != nil {
+		return err
+	}
+	for _, e := range g.Edges {
+		if err := cw.Write([]string{e.Source, e.Target, e.Type, strconv.Itoa(e.Multiplier), strconv.Itoa(e.Pairs)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type gexfDocument struct {
+	XMLName  xml.Name  `xml:"gexf"`
+	Xmlns    string    `xml:"xmlns,attr"`
+	XmlnsViz string    `xml:"xmlns:viz,attr"`
+	Version  string    `xml:"version,attr"`
+	Graph    gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+	Color     gexfColor      `xml:"viz:color"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    int            `xml:"weight,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+	Color     gexfColor      `xml:"viz:color"`
+	Thickness gexfThickness  `xml:"viz:thickness"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfColor struct {
+	R uint8 `xml:"r,attr"`
+	G uint8 `xml:"g,attr"`
+	B uint8 `xml:"b,attr"`
+}
+
+type gexfThickness struct {
+	Value int `xml:"value,attr"`
+}
+
+func (g *FusionGraph) WriteGEXF(w io.Writer) error {
+	doc := gexfDocument{
+		Xmlns:    "http://gexf.net/1.3",
+		XmlnsViz: "http://gexf.net/1.3/viz",
+		Version:  "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{ID: "rarity", Title: "rarity", Type: "string"},
+					{ID: "category", Title: "category", Type: "string"},
+					{ID: "skill", Title: "skill", Type: "string"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{ID: "type", Title: "type", Type: "string"},
+					{ID: "multiplier", Title: "multiplier", Type: "integer"},
+				}},
+			},
+			Nodes: make([]gexfNode, 0, len(g.Nodes)),
+			Edges: make([]gexfEdge, 0, len(g.Edges)),
+		},
+	}
+
+	for _, s := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    s.ID,
+			Label: s.Name,
+			AttValues: []gexfAttValue{
+				{For: "rarity", Value: string(s.Rarity)},
+				{For: "category", Value: string(s.Category)},
+				{For: "skill", Value: s.Skill},
+			},
+			Color: hexColor(s.scheme.rarityDef(s.Rarity).Color),
+		})
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: e.Source,
+			Target: e.Target,
+			Weight: e.Pairs,
+			Label:  edgeLabel(e),
+			AttValues: []gexfAttValue{
+				{For: "type", Value: e.Type},
+				{For: "multiplier", Value: strconv.Itoa(e.Multiplier)},
+			},
+			Color:     hexColor(fuseTypeColor(e.Type)),
+			Thickness: gexfThickness{Value: e.Multiplier},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func hexColor(hex string) gexfColor {
+	var c gexfColor
+	fmt.Sscanf(hex, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	return c
+}
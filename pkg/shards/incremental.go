@@ -0,0 +1,371 @@
+package shards
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// IncrementalProcessor keeps processed shard data in memory and applies config edits in place.
+// Only the fusion pairs and requirement matches an edit can affect are recomputed. Edits that
+// add or remove shards or touch the global settings fall back to a full rebuild.
+type IncrementalProcessor struct {
+	config *shardConfig
+	data   *ProcessedShardData
+	// Used for the initial processing and full rebuilds
+	opts ProcessOptions
+}
+
+type ShardPair struct {
+	Shard1 string `json:"shard1"`
+	Shard2 string `json:"shard2"`
+}
+
+type ChangeSet struct {
+	FullRebuild bool `json:"fullRebuild"`
+	// Shards whose config entry was added, removed or edited
+	Edited []string `json:"edited"`
+	// Ordered pairs whose fusion results or costs changed, including pairs that appeared or disappeared
+	Combinations []ShardPair `json:"combinations"`
+	// Requirement descriptions whose targets or matches changed
+	Requirements []string `json:"requirements"`
+}
+
+func (c *ChangeSet) IsEmpty() bool {
+	return len(c.Edited) == 0 && len(c.Combinations) == 0 && len(c.Requirements) == 0
+}
+
+func NewIncrementalProcessor(filePath string) (*IncrementalProcessor, error) {
+	return NewIncrementalProcessorWithOptions(filePath, ProcessOptions{})
+}
+
+func NewIncrementalProcessorWithOptions(filePath string, opts ProcessOptions) (*IncrementalProcessor, error) {
+	config, err := loadShardConfig(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading shard config: %w", err)
+	}
+	return newIncrementalProcessor(config, opts)
+}
+
+func newIncrementalProcessor(config *shardConfig, opts ProcessOptions) (*IncrementalProcessor, error) {
+	data, err := processShardConfigWithOptions(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &IncrementalProcessor{config: config, data: data, opts: opts}, nil
+}
+
+// Data returns the current processed data. It is updated in place by Reload, so callers that
+// need a stable view must not hold on to it across reloads.
+func (p *IncrementalProcessor) Data() *ProcessedShardData {
+	return p.data
+}
+
+// Reload re-reads the config file and applies whatever changed since the last load. On error
+// the previous data is kept untouched.
+func (p *IncrementalProcessor) Reload(filePath string) (*ChangeSet, error) {
+	config, err := loadShardConfig(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading shard config: %w", err)
+	}
+	return p.apply(config)
+}
+
+func (p *IncrementalProcessor) apply(config *shardConfig) (*ChangeSet, error) {
+	if !sameGlobalConfig(p.config, config) || !slices.Equal(slices.Sorted(maps.Keys(p.config.Shards)), slices.Sorted(maps.Keys(config.Shards))) {
+		return p.rebuild(config)
+	}
+
+	// Build every edited shard before touching anything, so a bad edit leaves the data as it was
+	edited := make([]*Shard, 0)
+	for _, id := range slices.Sorted(maps.Keys(config.Shards)) {
+		if reflect.DeepEqual(p.config.Shards[id], config.Shards[id]) {
+			continue
+		}
+		shard, err := buildShard(id, config.Shards[id], config, p.data.scheme)
+		if err != nil {
+			return nil, err
+		}
+		edited = append(edited, shard)
+	}
+	inputHash, err := hashShardConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &ChangeSet{
+		Edited:       make([]string, 0, len(edited)),
+		Combinations: make([]ShardPair, 0),
+		Requirements: make([]string, 0),
+	}
+	changedPairs := make(map[ShardPair]bool)
+	changedReqs := make(map[string]bool)
+	requirementInfo := p.data.SpecialRequirementInfo
+	for _, shard := range edited {
+		changes.Edited = append(changes.Edited, shard.ID)
+		if !p.replaceShard(shard) {
+			continue
+		}
+		for _, pair := range p.updateCombinations(shard, config) {
+			changedPairs[pair] = true
+		}
+		var reqs []string
+		requirementInfo, reqs = updateRequirementInfo(p.data.Shards, requirementInfo, shard)
+		for _, req := range reqs {
+			changedReqs[req] = true
+		}
+	}
+
+	p.config = config
+	p.data = assembleShardData(p.data.Shards, requirementInfo, config, p.data.scheme)
+	p.data.inputHash = inputHash
+
+	changes.Combinations = sortShardPairs(slices.Collect(maps.Keys(changedPairs)), p.data.Shards)
+	changes.Requirements = slices.Sorted(maps.Keys(changedReqs))
+	return changes, nil
+}
+
+func (p *IncrementalProcessor) rebuild(config *shardConfig) (*ChangeSet, error) {
+	data, err := processShardConfigWithOptions(config, p.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	editedSet := make(map[string]bool)
+	for id, shardData := range config.Shards {
+		if old, exists := p.config.Shards[id]; !exists || !reflect.DeepEqual(old, shardData) {
+			editedSet[id] = true
+		}
+	}
+	for id := range p.config.Shards {
+		if _, exists := config.Shards[id]; !exists {
+			editedSet[id] = true
+		}
+	}
+
+	changes := &ChangeSet{
+		FullRebuild:  true,
+		Edited:       slices.Sorted(maps.Keys(editedSet)),
+		Combinations: diffCombinations(p.data, data),
+		Requirements: diffRequirementInfo(p.data.SpecialRequirementInfo, data.SpecialRequirementInfo),
+	}
+	p.config = config
+	p.data = data
+	return changes, nil
+}
+
+func sameGlobalConfig(a *shardConfig, b *shardConfig) bool {
+	aGlobals, bGlobals := *a, *b
+	aGlobals.Shards, bGlobals.Shards = nil, nil
+	return reflect.DeepEqual(aGlobals, bGlobals)
+}
+
+// replaceShard swaps in the rebuilt shard, carrying over derived data that does not depend on
+// the edited fields. It reports whether the edit can affect any fusion.
+func (p *IncrementalProcessor) replaceShard(shard *Shard) bool {
+	old := p.data.Shards[shard.ID]
+	shard.WildcardTargets = old.WildcardTargets
+	shard.FuseCombinations = old.FuseCombinations
+	shard.BasicFuseTarget = old.BasicFuseTarget
+	p.data.Shards[shard.ID] = shard
+
+	return shard.Category != old.Category ||
+		shard.IsBasicFuseTarget != old.IsBasicFuseTarget ||
+		!maps.Equal(shard.Families, old.Families) ||
+		!reflect.DeepEqual(shard.SpecialFuses, old.SpecialFuses)
+}
+
+// updateCombinations recomputes the pairs an edit to shard can reach: every pair it is part of,
+// every pair involving a shard whose basic fuse target moved, and, when its special fuses
+// changed, every pair that meets any old or new requirement of those fuses.
+func (p *IncrementalProcessor) updateCombinations(shard *Shard, config *shardConfig) []ShardPair {
+	shards := p.data.Shards
+	old := p.config.Shards[shard.ID]
+
+	oldBasicTargets := make(map[string]string, len(shards))
+	for id, s := range shards {
+		oldBasicTargets[id] = s.BasicFuseTarget
+		s.BasicFuseTarget = ""
+	}
+	addBasicFusionTargets(shards)
+
+	// Wildcards with a category filter move when a shard changes category
+	oldWildcardTargets := make(map[string]map[string][]string, len(shards))
+	for id, s := range shards {
+		oldWildcardTargets[id] = s.WildcardTargets
+	}
+	addWildcardFusionTargets(shards, config, p.data.scheme)
+
+	rows := map[string]bool{shard.ID: true}
+	for id, s := range shards {
+		if s.BasicFuseTarget != oldBasicTargets[id] || !reflect.DeepEqual(s.WildcardTargets, oldWildcardTargets[id]) {
+			rows[id] = true
+		}
+	}
+
+	sortedShards := getSortedShards(shards)
+	pairs := make(map[ShardPair]bool)
+	for id := range rows {
+		for _, s := range sortedShards {
+			pairs[ShardPair{id, s.ID}] = true
+			pairs[ShardPair{s.ID, id}] = true
+		}
+	}
+
+	if !reflect.DeepEqual(old.SpecialFuses, shard.SpecialFuses) {
+		fuses := append(slices.Clone(old.SpecialFuses), shard.SpecialFuses...)
+		matching := make([]string, 0)
+		for _, s := range sortedShards {
+			for _, sf := range fuses {
+				if meetsSpecialFuseRequirement(s, &sf.Requirement1) || meetsSpecialFuseRequirement(s, &sf.Requirement2) {
+					matching = append(matching, s.ID)
+					break
+				}
+			}
+		}
+		for _, id1 := range matching {
+			for _, id2 := range matching {
+				pairs[ShardPair{id1, id2}] = true
+			}
+		}
+	}
+
+	combiner := newFuseCombiner(shards, config)
+	changed := make([]ShardPair, 0)
+	for pair := range pairs {
+		s1, s2 := shards[pair.Shard1], shards[pair.Shard2]
+		oldCombo, hadCombo := s1.FuseCombinations[s2.ID]
+		combo, ok := combiner.combine(s1, s2)
+		if hadCombo == ok && (!ok || reflect.DeepEqual(oldCombo, combo)) {
+			continue
+		}
+		if ok {
+			s1.FuseCombinations[s2.ID] = combo
+		} else {
+			delete(s1.FuseCombinations, s2.ID)
+		}
+		changed = append(changed, pair)
+	}
+	return changed
+}
+
+// updateRequirementInfo rebuilds the target lists, which is cheap, and only re-checks the edited
+// shard against requirements that already existed, since no other shard's attributes moved.
+func updateRequirementInfo(shards map[string]*Shard, oldInfo map[string]*requirementInfo, edited *Shard) (map[string]*requirementInfo, []string) {
+	sortedShards := getSortedShards(shards)
+	var matcher *requirementMatcher
+	info := make(map[string]*requirementInfo, len(oldInfo))
+	reqs := make(map[string]specialFuseRequirement)
+	for _, s := range sortedShards {
+		for _, fuse := range s.SpecialFuses {
+			for _, req := range []specialFuseRequirement{fuse.Requirement1, fuse.Requirement2} {
+				reqDesc := getRequirementDescription(&req)
+				if _, exists := info[reqDesc]; !exists {
+					info[reqDesc] = &requirementInfo{
+						Targets:     make([]string, 0),
+						Description: reqDesc,
+					}
+					reqs[reqDesc] = req
+				}
+				info[reqDesc].Targets = append(info[reqDesc].Targets, s.ID)
+			}
+		}
+	}
+
+	for reqDesc, newInfo := range info {
+		req := reqs[reqDesc]
+		old, existed := oldInfo[reqDesc]
+		if !existed {
+			if matcher == nil {
+				matcher = newRequirementMatcher(sortedShards)
+			}
+			newInfo.Matches = matcher.matches(matcher.compile(&req))
+			continue
+		}
+		matches := slices.DeleteFunc(slices.Clone(old.Matches), func(id string) bool {
+			return id == edited.ID
+		})
+		if meetsSpecialFuseRequirement(edited, &req) {
+			i, _ := slices.BinarySearchFunc(matches, edited, func(id string, target *Shard) int {
+				return getShardSortValue(shards[id]) - getShardSortValue(target)
+			})
+			matches = slices.Insert(matches, i, edited.ID)
+		}
+		newInfo.Matches = matches
+	}
+
+	return info, diffRequirementInfo(oldInfo, info)
+}
+
+func diffRequirementInfo(oldInfo map[string]*requirementInfo, newInfo map[string]*requirementInfo) []string {
+	changed := make([]string, 0)
+	for reqDesc, info := range newInfo {
+		if old, exists := oldInfo[reqDesc]; !exists || !reflect.DeepEqual(old, info) {
+			changed = append(changed, reqDesc)
+		}
+	}
+	for reqDesc := range oldInfo {
+		if _, exists := newInfo[reqDesc]; !exists {
+			changed = append(changed, reqDesc)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+func diffCombinations(oldData *ProcessedShardData, newData *ProcessedShardData) []ShardPair {
+	changed := make([]ShardPair, 0)
+	for id1, s1 := range newData.Shards {
+		var oldCombos map[string]FuseCombination
+		if old, exists := oldData.Shards[id1]; exists {
+			oldCombos = old.FuseCombinations
+		}
+		for id2, combo := range s1.FuseCombinations {
+			if oldCombo, exists := oldCombos[id2]; !exists || !reflect.DeepEqual(oldCombo, combo) {
+				changed = append(changed, ShardPair{id1, id2})
+			}
+		}
+		for id2 := range oldCombos {
+			if _, exists := s1.FuseCombinations[id2]; !exists {
+				changed = append(changed, ShardPair{id1, id2})
+			}
+		}
+	}
+	for id1, old := range oldData.Shards {
+		if _, exists := newData.Shards[id1]; exists {
+			continue
+		}
+		for id2 := range old.FuseCombinations {
+			changed = append(changed, ShardPair{id1, id2})
+		}
+	}
+
+	return sortShardPairs(changed, newData.Shards)
+}
+
+func sortShardPairs(pairs []ShardPair, shards map[string]*Shard) []ShardPair {
+	slices.SortFunc(pairs, func(a, b ShardPair) int {
+		if a.Shard1 != b.Shard1 {
+			return compareShardIds(a.Shard1, b.Shard1, shards)
+		}
+		return compareShardIds(a.Shard2, b.Shard2, shards)
+	})
+	return pairs
+}
+
+// Removed shards have no sort value, so pairs involving them fall back to plain ID order
+func compareShardIds(a string, b string, shards map[string]*Shard) int {
+	sa, okA := shards[a]
+	sb, okB := shards[b]
+	if okA && okB {
+		return getShardSortValue(sa) - getShardSortValue(sb)
+	}
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
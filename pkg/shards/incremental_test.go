@@ -0,0 +1,141 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func cloneConfig(t *testing.T, config *shardConfig) *shardConfig {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	var clone shardConfig
+	if err := json.Unmarshal(data, &clone); err != nil {
+		t.Fatalf("Failed to unmarshal config: %v", err)
+	}
+	return &clone
+}
+
+func marshalProcessed(t *testing.T, data *ProcessedShardData) []byte {
+	t.Helper()
+	out, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to marshal processed data: %v", err)
+	}
+	return out
+}
+
+func TestIncrementalMatchesFullRebuild(t *testing.T) {
+	baseConfig, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+
+	edits := []struct {
+		name        string
+		edit        func(c *shardConfig)
+		expectPairs bool
+	}{
+		{"rename only", func(c *shardConfig) {
+			s := c.Shards["C1"]
+			s.Name = "Renamed Grove"
+			c.Shards["C1"] = s
+		}, false},
+		{"change category", func(c *shardConfig) {
+			s := c.Shards["C19"]
+			s.Category = "water"
+			c.Shards["C19"] = s
+		}, true},
+		{"add family", func(c *shardConfig) {
+			s := c.Shards["R53"]
+			s.Families = append(s.Families, "shulker")
+			c.Shards["R53"] = s
+		}, true},
+		{"toggle basic fuse target", func(c *shardConfig) {
+			s := c.Shards["C14"]
+			s.IsBasicFuseTarget = !s.IsBasicFuseTarget
+			c.Shards["C14"] = s
+		}, true},
+		{"change special fuse", func(c *shardConfig) {
+			s := c.Shards["U5"]
+			s.SpecialFuses = []specialFuse{{
+				IsBoosted:    false,
+				Requirement1: specialFuseRequirement{Category: []string{"combat"}},
+				Requirement2: specialFuseRequirement{Rarity: []string{"rare+"}},
+			}}
+			c.Shards["U5"] = s
+		}, true},
+		{"remove special fuses", func(c *shardConfig) {
+			s := c.Shards["C1"]
+			s.SpecialFuses = nil
+			c.Shards["C1"] = s
+		}, true},
+		{"several edits at once", func(c *shardConfig) {
+			s := c.Shards["C2"]
+			s.Category = "combat"
+			c.Shards["C2"] = s
+			s = c.Shards["E26"]
+			s.Families = nil
+			c.Shards["E26"] = s
+		}, true},
+		{"global setting", func(c *shardConfig) {
+			c.SpecialFuseMultiplier = 3
+		}, true},
+	}
+
+	for _, tt := range edits {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := newIncrementalProcessor(cloneConfig(t, baseConfig), ProcessOptions{})
+			if err != nil {
+				t.Fatalf("Failed to build processor: %v", err)
+			}
+
+			edited := cloneConfig(t, baseConfig)
+			tt.edit(edited)
+			changes, err := processor.apply(edited)
+			if err != nil {
+				t.Fatalf("Failed to apply edit: %v", err)
+			}
+			if tt.expectPairs != (len(changes.Combinations) > 0) {
+				t.Errorf("Expected changed pairs: %v, got %d", tt.expectPairs, len(changes.Combinations))
+			}
+
+			full, err := processShardConfig(cloneConfig(t, edited))
+			if err != nil {
+				t.Fatalf("Failed to process edited config: %v", err)
+			}
+			if !bytes.Equal(marshalProcessed(t, processor.Data()), marshalProcessed(t, full)) {
+				t.Error("Incremental output differs from full rebuild")
+			}
+		})
+	}
+}
+
+func TestIncrementalRejectsInvalidEdit(t *testing.T) {
+	baseConfig, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	processor, err := newIncrementalProcessor(cloneConfig(t, baseConfig), ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Failed to build processor: %v", err)
+	}
+	before := marshalProcessed(t, processor.Data())
+
+	edited := cloneConfig(t, baseConfig)
+	s := edited.Shards["C1"]
+	s.Category = "space"
+	edited.Shards["C1"] = s
+	_, err = processor.apply(edited)
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) || shardErr.ID != "C1" || !errors.Is(err, ErrInvalidShardData) {
+		t.Fatalf("Expected invalid shard data error for C1, got %v", err)
+	}
+	if !bytes.Equal(before, marshalProcessed(t, processor.Data())) {
+		t.Error("Failed edit modified processed data")
+	}
+}
@@ -0,0 +1,137 @@
+package shards
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+)
+
+// ProcessedSchemaVersion is bumped whenever the layout of the processed output changes
+const ProcessedSchemaVersion = 1
+
+// ErrStaleOutput is returned when processed output doesn't match its input or its manifest
+var ErrStaleOutput = errors.New("stale processed shard data")
+
+// Manifest is written next to the processed output so deploys can tell whether it's up to date
+type Manifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Generator     string `json:"generator"`
+	InputHash     string `json:"inputHash"`
+	OutputHash    string `json:"outputHash"`
+	// Hashes of the fusion chunks of a split output, by path relative to the output
+	Chunks map[string]string `json:"chunks,omitempty"`
+}
+
+// ManifestPath is where the manifest of outFile goes: shards_processed.json gets
+// shards_processed.manifest.json
+func ManifestPath(outFile string) string {
+	return strings.TrimSuffix(outFile, filepath.Ext(outFile)) + ".manifest.json"
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hashShardConfig hashes the config as parsed, so reformatting the input or converting it to
+// another format doesn't count as a change
+func hashShardConfig(config *shardConfig) (string, error) {
+	content, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error hashing shard config: %w", err)
+	}
+	return hashContent(content), nil
+}
+
+// generatorVersion names the build that produced the output, as stamped by the go command
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Path + " " + info.Main.Version
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && !strings.Contains(info.Main.Version, revision[:min(12, len(revision))]) {
+		version += " " + revision
+		if modified {
+			version += "+dirty"
+		}
+	}
+	return version
+}
+
+func writeManifest(outFile string, inputHash string, output []byte, chunks map[string]string) error {
+	manifest := Manifest{
+		SchemaVersion: ProcessedSchemaVersion,
+		Generator:     generatorVersion(),
+		InputHash:     inputHash,
+		OutputHash:    hashContent(output),
+		Chunks:        chunks,
+	}
+	content, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting manifest: %w", err)
+	}
+	return writeFileAtomic(ManifestPath(outFile), append(content, '\n'))
+}
+
+// CheckShardData reports whether outFile is the current processed output for inFile: its manifest
+// must have the current schema version, the hash of inFile and the hashes of outFile and its
+// chunks as they are now
+func CheckShardData(inFile string, outFile string) (*Manifest, error) {
+	content, err := os.ReadFile(ManifestPath(outFile))
+	if err != nil {
+		return nil, errorOf(ErrStaleOutput, "error reading manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errorOf(ErrStaleOutput, "error parsing manifest: %w", err)
+	}
+	if manifest.SchemaVersion != ProcessedSchemaVersion {
+		return &manifest, errorOf(ErrStaleOutput, "output has schema version %d, expected %d", manifest.SchemaVersion, ProcessedSchemaVersion)
+	}
+
+	output, err := os.ReadFile(outFile)
+	if err != nil {
+		return &manifest, errorOf(ErrStaleOutput, "error reading output: %w", err)
+	}
+	if hashContent(output) != manifest.OutputHash {
+		return &manifest, errorOf(ErrStaleOutput, "%s was changed after it was generated", outFile)
+	}
+	for path, hash := range manifest.Chunks {
+		chunk, err := os.ReadFile(filepath.Join(filepath.Dir(outFile), filepath.FromSlash(path)))
+		if err != nil {
+			return &manifest, errorOf(ErrStaleOutput, "error reading chunk: %w", err)
+		}
+		if hashContent(chunk) != hash {
+			return &manifest, errorOf(ErrStaleOutput, "%s was changed after it was generated", path)
+		}
+	}
+
+	config, err := loadShardConfig(inFile)
+	if err != nil {
+		return &manifest, fmt.Errorf("error loading shard config: %w", err)
+	}
+	inputHash, err := hashShardConfig(config)
+	if err != nil {
+		return &manifest, err
+	}
+	if inputHash != manifest.InputHash {
+		return &manifest, errorOf(ErrStaleOutput, "%s changed since %s was generated", inFile, outFile)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,66 @@
+package shards
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "shards_processed.json")
+	data, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	if err := WriteShardData(data, out, OutputOptions{}); err != nil {
+		t.Fatalf("Failed to write shard data: %v", err)
+	}
+	first, _ := os.ReadFile(out)
+
+	// Map iteration order differs between runs, so a few runs are enough to catch unstable output
+	for range 3 {
+		again, err := ProcessShards(testShardDataLocation)
+		if err != nil {
+			t.Fatalf("Failed to process shard data: %v", err)
+		}
+		if err := WriteShardData(again, out, OutputOptions{}); err != nil {
+			t.Fatalf("Failed to write shard data: %v", err)
+		}
+		if written, _ := os.ReadFile(out); !bytes.Equal(written, first) {
+			t.Fatalf("Processing the same input twice gave different output")
+		}
+	}
+
+	manifest, err := CheckShardData(testShardDataLocation, out)
+	if err != nil {
+		t.Fatalf("Fresh output should pass the check: %v", err)
+	}
+	if manifest.SchemaVersion != ProcessedSchemaVersion || manifest.OutputHash != hashContent(first) {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	edited := config.Shards["C1"]
+	edited.EffectMax++
+	config.Shards["C1"] = edited
+	in := filepath.Join(dir, "shards.json")
+	if err := writeShardConfig(config, in, "json"); err != nil {
+		t.Fatalf("Failed to write edited config: %v", err)
+	}
+	if _, err := CheckShardData(in, out); !errors.Is(err, ErrStaleOutput) {
+		t.Errorf("Expected edited input to make the output stale, got %v", err)
+	}
+
+	if err := os.WriteFile(out, append(first, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckShardData(testShardDataLocation, out); !errors.Is(err, ErrStaleOutput) {
+		t.Errorf("Expected edited output to be stale, got %v", err)
+	}
+}
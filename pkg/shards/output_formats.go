@@ -0,0 +1,193 @@
+package shards
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Processed data is written as JSON unless MessagePack is asked for
+const FormatMsgpack = "msgpack"
+
+const (
+	// LayoutSingle writes everything to one file
+	LayoutSingle = "single"
+	// LayoutSplit writes an index without fusion combinations plus one chunk of them per shard
+	LayoutSplit = "split"
+	// EncodingFull writes fusion combinations as objects keyed by shard ID
+	EncodingFull = "full"
+	// EncodingCompact writes fusion combinations as columns of interned shard IDs and fuse types
+	EncodingCompact = "compact"
+)
+
+// OutputOptions pick how processed data is written. The zero value is the single JSON file the
+// shard calculator imports.
+type OutputOptions struct {
+	Layout   string
+	Encoding string
+	Format   string
+}
+
+func (o OutputOptions) withDefaults() (OutputOptions, error) {
+	o.Layout = cmp.Or(o.Layout, LayoutSingle)
+	o.Encoding = cmp.Or(o.Encoding, EncodingFull)
+	o.Format = cmp.Or(o.Format, FormatJSON)
+	switch {
+	case o.Layout != LayoutSingle && o.Layout != LayoutSplit:
+		return o, fmt.Errorf("unknown output layout %q", o.Layout)
+	case o.Encoding != EncodingFull && o.Encoding != EncodingCompact:
+		return o, fmt.Errorf("unknown output encoding %q", o.Encoding)
+	case o.Format != FormatJSON && o.Format != FormatMsgpack:
+		return o, fmt.Errorf("unknown output format %q", o.Format)
+	}
+	return o, nil
+}
+
+// outputInfo tells clients how to read an index that isn't in the default layout
+type outputInfo struct {
+	Layout   string `json:"layout"`
+	Encoding string `json:"encoding"`
+	// Path of each shard's chunk relative to the index, with {id} standing for the shard ID
+	FusionChunks string `json:"fusionChunks,omitempty"`
+	// Interned shard IDs and fuse types that the compact columns index into
+	IDs       []string `json:"ids,omitempty"`
+	FuseTypes []string `json:"fuseTypes,omitempty"`
+}
+
+type outputIndex struct {
+	*ProcessedShardData
+	Output *outputInfo `json:"output,omitempty"`
+	// Compact fusion combinations by shard ID, when they aren't split into chunks
+	Fusions map[string]*compactFusions `json:"fusions,omitempty"`
+}
+
+// compactFusions holds one shard's fusion combinations as parallel columns. Combination i is with
+// shard ids[Shard2[i]] and has ResultCounts[i] results, read in order from the result columns.
+type compactFusions struct {
+	Shard2       []int `json:"shard2"`
+	Cost1        []int `json:"cost1"`
+	Cost2        []int `json:"cost2"`
+	ResultCounts []int `json:"resultCounts"`
+	ResultIDs    []int `json:"resultIds"`
+	ResultTypes  []int `json:"resultTypes"`
+	Multipliers  []int `json:"multipliers"`
+}
+
+// fusionInterner numbers shard IDs in shard order and fuse types in the order they first appear
+type fusionInterner struct {
+	shards []*Shard
+	ids    map[string]int
+	types  map[string]int
+	info   *outputInfo
+}
+
+func newFusionInterner(shards map[string]*Shard, info *outputInfo) *fusionInterner {
+	in := &fusionInterner{
+		shards: getSortedShards(shards),
+		ids:    make(map[string]int, len(shards)),
+		types:  make(map[string]int),
+		info:   info,
+	}
+	for i, s := range in.shards {
+		in.ids[s.ID] = i
+		info.IDs = append(info.IDs, s.ID)
+	}
+	return in
+}
+
+func (in *fusionInterner) fuseType(t string) int {
+	i, exists := in.types[t]
+	if !exists {
+		i = len(in.info.FuseTypes)
+		in.types[t] = i
+		in.info.FuseTypes = append(in.info.FuseTypes, t)
+	}
+	return i
+}
+
+func (in *fusionInterner) compact(s *Shard) *compactFusions {
+	c := &compactFusions{}
+	for _, s2 := range in.shards {
+		combo, ok := s.FuseCombinations[s2.ID]
+		if !ok {
+			continue
+		}
+		c.Shard2 = append(c.Shard2, in.ids[s2.ID])
+		c.Cost1 = append(c.Cost1, combo.Cost1)
+		c.Cost2 = append(c.Cost2, combo.Cost2)
+		c.ResultCounts = append(c.ResultCounts, len(combo.Results))
+		for _, result := range combo.Results {
+			c.ResultIDs = append(c.ResultIDs, in.ids[result.ID])
+			c.ResultTypes = append(c.ResultTypes, in.fuseType(result.Type))
+			c.Multipliers = append(c.Multipliers, result.Multiplier)
+		}
+	}
+	return c
+}
+
+// encodeOutput writes v in the given format. Full JSON is indented like it always was; compact
+// JSON isn't, since nobody reads columns of numbers by eye.
+func encodeOutput(v any, opts OutputOptions) ([]byte, error) {
+	if opts.Format == FormatMsgpack {
+		var buf bytes.Buffer
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetCustomStructTag("json")
+		encoder.SetSortMapKeys(true)
+		encoder.UseCompactInts(true)
+		if err := encoder.Encode(v); err != nil {
+			return nil, fmt.Errorf("error encoding MessagePack: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	if opts.Encoding == EncodingCompact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// chunkDir is where the chunks of a split index go, relative to the index
+func chunkDir(outFile string) string {
+	base := filepath.Base(outFile)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_fusions"
+}
+
+// writeChunks writes each shard's chunk and removes chunks of shards that no longer exist. It
+// returns the hash of every chunk by its path relative to the index.
+func writeChunks(outFile string, chunks map[string]any, opts OutputOptions) (map[string]string, error) {
+	dir := filepath.Join(filepath.Dir(outFile), chunkDir(outFile))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating chunk directory: %w", err)
+	}
+	ext := filepath.Ext(outFile)
+	hashes := make(map[string]string, len(chunks))
+	for id, chunk := range chunks {
+		content, err := encodeOutput(chunk, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(filepath.Join(dir, id+ext), content); err != nil {
+			return nil, err
+		}
+		hashes[chunkDir(outFile)+"/"+id+ext] = hashContent(content)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading chunk directory: %w", err)
+	}
+	for _, entry := range entries {
+		id, isChunk := strings.CutSuffix(entry.Name(), ext)
+		if _, current := chunks[id]; isChunk && !current && !entry.IsDir() {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, fmt.Errorf("error removing old chunk: %w", err)
+			}
+		}
+	}
+	return hashes, nil
+}
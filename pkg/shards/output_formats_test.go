@@ -0,0 +1,117 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// expandFusions turns compact columns back into the combinations the full encoding writes
+func expandFusions(shard1 string, c *compactFusions, info *outputInfo) map[string]FuseCombination {
+	combos := make(map[string]FuseCombination, len(c.Shard2))
+	next := 0
+	for i, s2 := range c.Shard2 {
+		combo := FuseCombination{Shard1: shard1, Cost1: c.Cost1[i], Shard2: info.IDs[s2], Cost2: c.Cost2[i]}
+		for range c.ResultCounts[i] {
+			combo.Results = append(combo.Results, FuseResult{
+				Type:       info.FuseTypes[c.ResultTypes[next]],
+				ID:         info.IDs[c.ResultIDs[next]],
+				Multiplier: c.Multipliers[next],
+			})
+			next++
+		}
+		combos[combo.Shard2] = combo
+	}
+	return combos
+}
+
+func TestSplitCompactOutput(t *testing.T) {
+	data, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "shards_processed.json")
+	stale := filepath.Join(dir, "shards_processed_fusions", "X99.json")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteShardData(data, out, OutputOptions{Layout: LayoutSplit, Encoding: EncodingCompact}); err != nil {
+		t.Fatalf("Failed to write shard data: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Chunk of a shard that no longer exists should be removed")
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index struct {
+		Shards map[string]*Shard `json:"shards"`
+		Output *outputInfo       `json:"output"`
+	}
+	if err := json.Unmarshal(content, &index); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if index.Output == nil || len(index.Output.IDs) != len(data.Shards) || len(index.Shards) != len(data.Shards) {
+		t.Fatalf("Unexpected index output info: %+v", index.Output)
+	}
+	for id, s := range data.Shards {
+		if len(index.Shards[id].FuseCombinations) > 0 {
+			t.Fatalf("Index should not hold fusion combinations, %s does", id)
+		}
+		chunk, err := os.ReadFile(filepath.Join(dir, strings.ReplaceAll(index.Output.FusionChunks, "{id}", id)))
+		if err != nil {
+			t.Fatalf("Failed to read chunk of %s: %v", id, err)
+		}
+		var compact compactFusions
+		if err := json.Unmarshal(chunk, &compact); err != nil {
+			t.Fatalf("Failed to parse chunk of %s: %v", id, err)
+		}
+		if got := expandFusions(id, &compact, index.Output); !reflect.DeepEqual(got, s.FuseCombinations) {
+			t.Fatalf("Fusion combinations of %s don't survive the compact encoding", id)
+		}
+	}
+	if _, err := CheckShardData(testShardDataLocation, out); err != nil {
+		t.Errorf("Split output should pass the check: %v", err)
+	}
+}
+
+func TestMsgpackOutput(t *testing.T) {
+	data, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "shards_processed.msgpack")
+	if err := WriteShardData(data, out, OutputOptions{Format: FormatMsgpack}); err != nil {
+		t.Fatalf("Failed to write shard data: %v", err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := msgpack.NewDecoder(bytes.NewReader(content))
+	decoder.SetCustomStructTag("json")
+	var decoded ProcessedShardData
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode MessagePack output: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Shards["R53"].FuseCombinations, data.Shards["R53"].FuseCombinations) ||
+		!reflect.DeepEqual(decoded.SpecialRequirements, data.SpecialRequirements) {
+		t.Errorf("MessagePack output doesn't decode to the processed data")
+	}
+
+	if err := WriteShardData(data, out, OutputOptions{Format: "xml"}); err == nil {
+		t.Errorf("Expected an unknown format to be rejected")
+	}
+}
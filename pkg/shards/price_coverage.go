@@ -0,0 +1,134 @@
+package shards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+)
+
+// EstimateCheapestFusion prices a shard at the cheapest way to fuse it from priced shards
+const EstimateCheapestFusion = "cheapestFusion"
+
+type ShardPrice struct {
+	Price float64 `json:"price"`
+	// Estimated prices aren't on the bazaar and were worked out with Method
+	Estimated bool        `json:"estimated"`
+	Method    string      `json:"method,omitempty"`
+	Fusion    *FusionPath `json:"fusion,omitempty"`
+	// Where a quoted price came from, like prices.SourceBazaar or prices.SourceAuctions
+	Source string `json:"source,omitempty"`
+}
+
+type PriceCoverage struct {
+	Shards int `json:"shards"`
+	Quoted int `json:"quoted"`
+	// Shard IDs priced by estimation, and shard IDs that couldn't be priced at all
+	Estimated []string `json:"estimated"`
+	Unpriced  []string `json:"unpriced"`
+	// Quoted bazaar IDs that no shard has
+	Unmatched []string `json:"unmatched"`
+}
+
+// PriceSheet is what the pricing step writes to shard_prices.json. ShardPrices keeps every quoted
+// price, from the bazaar or any other source the prices came from; Prices says which source each
+// quote came from and also has an estimate for every other shard that can be fused.
+type PriceSheet struct {
+	Timestamp   int64                 `json:"timestamp"`
+	ShardPrices map[string]float64    `json:"shardPrices"`
+	Prices      map[string]ShardPrice `json:"prices"`
+	Coverage    *PriceCoverage        `json:"coverage"`
+}
+
+// CompletePrices fills in a price for every shard the quotes don't cover, at the cheapest fusion
+// from shards that are priced. Shards estimated this way can price further shards in turn. Both
+// quotes and the result are keyed by bazaar ID.
+func (d *ProcessedShardData) CompletePrices(quotes map[string]float64) (map[string]ShardPrice, *PriceCoverage) {
+	coverage := &PriceCoverage{
+		Shards:    len(d.Shards),
+		Estimated: make([]string, 0),
+		Unpriced:  make([]string, 0),
+		Unmatched: make([]string, 0),
+	}
+	sorted := getSortedShards(d.Shards)
+	known := make(map[string]float64, len(d.Shards))
+	prices := make(map[string]ShardPrice, len(d.Shards))
+	for _, s := range sorted {
+		if price, ok := quotes[s.BazaarId]; ok && price > 0 {
+			known[s.BazaarId] = price
+			prices[s.BazaarId] = ShardPrice{Price: price}
+			coverage.Quoted++
+		}
+	}
+
+	for added := true; added; {
+		added = false
+		cheapest := d.CheapestFusions(known)
+		for _, s := range sorted {
+			path, ok := cheapest[s.ID]
+			if _, priced := known[s.BazaarId]; priced || !ok {
+				continue
+			}
+			known[s.BazaarId] = path.PricePerShard
+			prices[s.BazaarId] = ShardPrice{
+				Price:     path.PricePerShard,
+				Estimated: true,
+				Method:    EstimateCheapestFusion,
+				Fusion:    &path,
+			}
+			coverage.Estimated = append(coverage.Estimated, s.ID)
+			added = true
+		}
+	}
+
+	bazaarIds := make(map[string]bool, len(d.Shards))
+	for _, s := range sorted {
+		bazaarIds[s.BazaarId] = true
+		if _, priced := known[s.BazaarId]; !priced {
+			coverage.Unpriced = append(coverage.Unpriced, s.ID)
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(quotes)) {
+		if !bazaarIds[id] {
+			coverage.Unmatched = append(coverage.Unmatched, id)
+		}
+	}
+	return prices, coverage
+}
+
+// NewPriceSheet prices every shard from the quotes in snap, noting where each quote came from
+func (d *ProcessedShardData) NewPriceSheet(snap *prices.Snapshot) *PriceSheet {
+	shardPrices, coverage := d.CompletePrices(snap.Prices)
+	for id, price := range shardPrices {
+		if !price.Estimated {
+			price.Source = snap.SourceOf(id)
+			shardPrices[id] = price
+		}
+	}
+	return &PriceSheet{
+		Timestamp:   snap.Time.Unix(),
+		ShardPrices: snap.Prices,
+		Prices:      shardPrices,
+		Coverage:    coverage,
+	}
+}
+
+// LoadPriceSheet gets quotes from provider and prices every shard from them
+func (d *ProcessedShardData) LoadPriceSheet(ctx context.Context, provider prices.PriceProvider) (*PriceSheet, error) {
+	snap, err := provider.Prices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting prices: %w", err)
+	}
+	return d.NewPriceSheet(snap), nil
+}
+
+func WritePriceSheet(sheet *PriceSheet, outFile string) error {
+	content, err := json.MarshalIndent(sheet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting JSON: %w", err)
+	}
+	return writeFileAtomic(outFile, content)
+}
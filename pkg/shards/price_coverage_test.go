@@ -0,0 +1,70 @@
+package shards
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andu2/andu-skyblock-tools/pkg/prices"
+)
+
+func TestCompletePrices(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	quotes := map[string]float64{"SHARD_NOT_A_SHARD": 5}
+	for id, s := range shardData.Shards {
+		// Leave R58 and every shard numbered 10 off the bazaar
+		if id != "R58" && s.Number != 10 {
+			quotes[s.BazaarId] = 1000 + float64(s.Number)
+		}
+	}
+	prices, coverage := shardData.CompletePrices(quotes)
+
+	if coverage.Quoted+len(coverage.Estimated)+len(coverage.Unpriced) != coverage.Shards || coverage.Shards != len(shardData.Shards) {
+		t.Errorf("Coverage doesn't add up: %+v", coverage)
+	}
+	if !slices.Equal(coverage.Unmatched, []string{"SHARD_NOT_A_SHARD"}) {
+		t.Errorf("Expected SHARD_NOT_A_SHARD to be unmatched, got %v", coverage.Unmatched)
+	}
+	if !slices.Contains(coverage.Estimated, "R58") {
+		t.Fatalf("Expected R58 to be estimated, got %+v", coverage)
+	}
+	r58 := prices[shardData.Shards["R58"].BazaarId]
+	if !r58.Estimated || r58.Method != EstimateCheapestFusion || r58.Fusion == nil || r58.Fusion.Target != "R58" {
+		t.Errorf("Unexpected R58 price: %+v", r58)
+	}
+	for _, id := range coverage.Estimated {
+		p := prices[shardData.Shards[id].BazaarId]
+		in1, in2 := shardData.Shards[p.Fusion.Shard1], shardData.Shards[p.Fusion.Shard2]
+		expected := (prices[in1.BazaarId].Price*float64(p.Fusion.Cost1) + prices[in2.BazaarId].Price*float64(p.Fusion.Cost2)) / float64(p.Fusion.Multiplier)
+		if p.Price != expected || p.Price <= 0 {
+			t.Errorf("%s should cost what its fusion inputs cost, got %v, expected %v", id, p.Price, expected)
+		}
+	}
+	if quoted := prices[shardData.Shards["C1"].BazaarId]; quoted.Estimated || quoted.Price != 1001 {
+		t.Errorf("Quoted prices should be kept as they are, got %+v", quoted)
+	}
+}
+
+func TestNewPriceSheet(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	c1, c2 := shardData.Shards["C1"].BazaarId, shardData.Shards["C2"].BazaarId
+	snap := &prices.Snapshot{
+		Prices:  map[string]float64{c1: 10, c2: 20},
+		Source:  prices.SourceBazaar,
+		Sources: map[string]string{c2: prices.SourceAuctions},
+	}
+	sheet := shardData.NewPriceSheet(snap)
+	if sheet.Prices[c1].Source != prices.SourceBazaar || sheet.Prices[c2].Source != prices.SourceAuctions {
+		t.Errorf("Expected quotes to keep their source, got %+v and %+v", sheet.Prices[c1], sheet.Prices[c2])
+	}
+	for id, price := range sheet.Prices {
+		if price.Estimated && price.Source != "" {
+			t.Errorf("%s is estimated, so it shouldn't have a source: %+v", id, price)
+		}
+	}
+}
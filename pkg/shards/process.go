@@ -1,14 +1,16 @@
 package shards
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
-	"os"
 	"slices"
-	"strconv"
+	"strings"
 )
 
 type shardConfig struct {
+	Rarities              []rarityDef                `json:"rarities,omitempty"`
+	Categories            []categoryDef              `json:"categories,omitempty"`
 	Skills                []string                   `json:"skills"`
 	Families              []string                   `json:"families"`
 	SourceTypes           []string                   `json:"sourceTypes"`
@@ -16,181 +18,267 @@ type shardConfig struct {
 	CostToMax             map[string]int             `json:"costToMax"`
 	FamilyFuseCost        map[string]int             `json:"familyFuseCost"`
 	SpecialFuseMultiplier int                        `json:"specialFuseMultiplier"`
-	Shards                map[string]shardConfigData `json:"shards"`
+	Wildcards             []wildcardConfig           `json:"wildcards,omitempty"`
+	Shards                map[string]shardConfigData `json:"shards,omitempty"`
 }
 
 type shardConfigData struct {
 	Name              string        `json:"name"`
 	BazaarId          string        `json:"bazaarId"`
+	Aliases           []string      `json:"aliases,omitempty"`
 	AttributeName     string        `json:"attributeName"`
 	EffectDescription string        `json:"effectDescription"`
 	EffectMax         float64       `json:"effectMax"`
-	Effect2Max        float64       `json:"effect2Max"`
-	EffectTags        []string      `json:"effectTags"`
+	Effect2Max        float64       `json:"effect2Max,omitempty"`
+	Effects           []EffectStat  `json:"effects,omitempty"`
+	EffectTags        []string      `json:"effectTags,omitempty"`
 	Category          string        `json:"category"`
 	Skill             string        `json:"skill"`
-	Families          []string      `json:"families"`
-	IsBasicFuseTarget bool          `json:"isBasicFuseTarget"`
-	Sources           []source      `json:"sources"`
-	SpecialFuses      []specialFuse `json:"specialFuses"`
+	Families          []string      `json:"families,omitempty"`
+	IsBasicFuseTarget bool          `json:"isBasicFuseTarget,omitempty"`
+	Sources           []source      `json:"sources,omitempty"`
+	SpecialFuses      []specialFuse `json:"specialFuses,omitempty"`
 }
 
 func loadShardConfig(filePath string) (*shardConfig, error) {
-	data, err := os.ReadFile(filePath)
+	data, err := readConfigJSON(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read shard data file: %v", err)
+		return nil, err
 	}
+	return parseShardConfig(data)
+}
 
+func parseShardConfig(data []byte) (*shardConfig, error) {
 	var config shardConfig
-	err = json.Unmarshal(data, &config)
+	err := json.Unmarshal(data, &config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal shard data: %v", err)
+		return nil, errorOf(ErrInvalidShardData, "failed to unmarshal shard data: %w", err)
 	}
 
 	return &config, nil
 }
 
-type processedShardData struct {
+type ProcessedShardData struct {
+	Rarities               []rarityDef                 `json:"rarities"`
+	Categories             []categoryDef               `json:"categories"`
 	FamilyShards           map[string][]string         `json:"familyShards"`
 	CategoryShards         map[category][]string       `json:"categoryShards"`
 	SkillShards            map[string][]string         `json:"skillShards"`
 	RarityShards           map[rarity][]string         `json:"rarityShards"`
 	TagShards              map[string][]string         `json:"tagShards"`
+	StatShards             map[string][]string         `json:"statShards"`
 	SourceTypeShards       map[string][]string         `json:"sourceTypeShards"`
 	CostToMax              map[string]int              `json:"costToMax"`
-	Shards                 map[string]*shard           `json:"shards"`
+	LevelCosts             map[rarity][]int            `json:"levelCosts"`
+	Shards                 map[string]*Shard           `json:"shards"`
 	SpecialRequirements    []string                    `json:"specialRequirements"`
 	SpecialRequirementInfo map[string]*requirementInfo `json:"specialRequirementInfo"`
+
+	scheme    *shardScheme
+	inputHash string
+}
+
+// RarityRank orders rarities from 1 for the lowest up, and 0 for anything that isn't a rarity
+func (d *ProcessedShardData) RarityRank(r string) int {
+	return d.scheme.rank(rarity(r))
+}
+
+type ProcessOptions struct {
+	// Goroutines computing fusion combinations. 0 uses one per CPU and 1 computes them serially.
+	// The output is the same either way.
+	Workers int
+}
+
+func ProcessShards(filePath string) (*ProcessedShardData, error) {
+	return ProcessShardsWithOptions(filePath, ProcessOptions{})
 }
 
-func processShards(filePath string) (*processedShardData, error) {
+func ProcessShardsWithOptions(filePath string, opts ProcessOptions) (*ProcessedShardData, error) {
 	config, err := loadShardConfig(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("error loading shard config: %v", err)
+		return nil, fmt.Errorf("error loading shard config: %w", err)
 	}
+	return processShardConfigWithOptions(config, opts)
+}
+
+// ProcessShardJSON processes shard data that is already in memory, like the copy embedded in the
+// skyblock binary
+func ProcessShardJSON(content []byte, opts ProcessOptions) (*ProcessedShardData, error) {
+	config, err := parseShardConfig(content)
+	if err != nil {
+		return nil, fmt.Errorf("error loading shard config: %w", err)
+	}
+	return processShardConfigWithOptions(config, opts)
+}
+
+func processShardConfig(config *shardConfig) (*ProcessedShardData, error) {
+	return processShardConfigWithOptions(config, ProcessOptions{})
+}
+
+func processShardConfigWithOptions(config *shardConfig, opts ProcessOptions) (*ProcessedShardData, error) {
+	inputHash, err := hashShardConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := newShardScheme(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWildcards(config, scheme); err != nil {
+		return nil, err
+	}
+	shards := make(map[string]*Shard)
+	for id, data := range config.Shards {
+		shard, err := buildShard(id, data, config, scheme)
+		if err != nil {
+			return nil, err
+		}
+		shards[id] = shard
+	}
+
+	addBasicFusionTargets(shards)
+	addWildcardFusionTargets(shards, config, scheme)
+	addFuseCombos(shards, config, opts.Workers)
+
+	requirementInfo := collectRequirementInfo(shards)
+	data := assembleShardData(shards, requirementInfo, config, scheme)
+	data.inputHash = inputHash
+	return data, nil
+}
 
+func buildShard(id string, data shardConfigData, config *shardConfig, scheme *shardScheme) (*Shard, error) {
+	rarity, number, err := scheme.parseID(id)
+	if err != nil {
+		return nil, &ShardError{ID: id, Op: "processing", Err: err}
+	}
+
+	category, err := scheme.parseCategory(data.Category)
+	if err != nil {
+		return nil, &ShardError{ID: id, Op: "validating category", Err: err}
+	}
+
+	families, err := processFamilies(data.Families, config.Families)
+	if err != nil {
+		return nil, &ShardError{ID: id, Op: "processing families", Err: err}
+	}
+
+	effectTags, err := processTags(data.EffectTags, config.EffectTags)
+	if err != nil {
+		return nil, &ShardError{ID: id, Op: "processing effect tags", Err: err}
+	}
+
+	if err := validateSpecialFuses(data.SpecialFuses, config, scheme); err != nil {
+		return nil, &ShardError{ID: id, Op: "validating special fuses", Err: err}
+	}
+
+	sources := data.Sources
+	if len(sources) == 0 {
+		sources = []source{
+			{SourceType: "fusionOnly", SourceDesc: "Fusion Only"},
+		}
+	}
+
+	specialFusesDesc := make([][]string, 0, len(data.SpecialFuses))
+	for _, sf := range data.SpecialFuses {
+		desc1 := getRequirementDescription(&sf.Requirement1)
+		desc2 := getRequirementDescription(&sf.Requirement2)
+		desc := []string{desc1, desc2}
+		specialFusesDesc = append(specialFusesDesc, desc)
+	}
+
+	stats, _, err := effectStatsFor(data, config)
+	if err != nil {
+		return nil, &ShardError{ID: id, Op: "processing effects", Err: err}
+	}
+
+	shard := &Shard{
+		ID:                id,
+		BazaarId:          data.BazaarId,
+		Name:              data.Name,
+		Aliases:           data.Aliases,
+		Rarity:            rarity,
+		Number:            number,
+		AttributeName:     data.AttributeName,
+		EffectDescription: data.EffectDescription,
+		EffectMax:         data.EffectMax,
+		Effect2Max:        data.Effect2Max,
+		Stats:             stats,
+		EffectTags:        effectTags,
+		Category:          category,
+		Skill:             data.Skill,
+		Families:          families,
+		IsBasicFuseTarget: data.IsBasicFuseTarget,
+		Sources:           sources,
+		SpecialFuses:      data.SpecialFuses,
+		SpecialFusesDesc:  specialFusesDesc,
+
+		// The rest get filled in later
+		FuseCombinations: make(map[string]FuseCombination),
+
+		scheme: scheme,
+	}
+
+	for _, family := range data.Families {
+		shard.Families[family] = true
+	}
+
+	return shard, nil
+}
+
+func assembleShardData(shards map[string]*Shard, requirementInfo map[string]*requirementInfo, config *shardConfig, scheme *shardScheme) *ProcessedShardData {
 	// Categorize these in a bunch of ways to minimize front-end logic
 	familyShards := make(map[string][]string, len(config.Families))
 	for _, family := range config.Families {
 		familyShards[family] = make([]string, 0, 20)
 	}
-	categoryShards := make(map[category][]string, 3)
-	for _, cat := range []category{categoryForest, categoryWater, categoryCombat} {
-		categoryShards[cat] = make([]string, 0, 100)
+	categoryShards := make(map[category][]string, len(scheme.categories))
+	for _, cat := range scheme.categories {
+		categoryShards[cat.ID] = make([]string, 0, 100)
 	}
 	skillShards := make(map[string][]string, len(config.Skills))
 	for _, skill := range config.Skills {
 		skillShards[skill] = make([]string, 0, 50)
 	}
-	rarityShards := make(map[rarity][]string, 5)
-	for _, r := range []rarity{rarityCommon, rarityUncommon, rarityRare, rarityEpic, rarityLegendary} {
-		rarityShards[r] = make([]string, 0, 100)
+	rarityShards := make(map[rarity][]string, len(scheme.rarities))
+	for _, r := range scheme.rarities {
+		rarityShards[r.ID] = make([]string, 0, 100)
 	}
 	tagShards := make(map[string][]string, len(config.EffectTags))
 	for _, tag := range config.EffectTags {
 		tagShards[tag] = make([]string, 0, 10)
 	}
+	statShards := make(map[string][]string)
 	sourceTypeShards := make(map[string][]string, len(config.SourceTypes))
 	for _, sourceType := range config.SourceTypes {
 		sourceTypeShards[sourceType] = make([]string, 0, 100)
 	}
 
-	shards := make(map[string]*shard)
-	for id, data := range config.Shards {
-		rarity, number, err := processId(id)
-		if err != nil {
-			return nil, fmt.Errorf("error processing shard ID %s: %v", id, err)
-		}
-
-		category, err := validateCategory(data.Category)
-		if err != nil {
-			return nil, fmt.Errorf("error validating category for shard ID %s: %v", id, err)
-		}
-
-		families, err := processFamilies(data.Families, config.Families)
-		if err != nil {
-			return nil, fmt.Errorf("error processing families for shard ID %s: %v", id, err)
-		}
-
-		effectTags, err := processTags(data.EffectTags, config.EffectTags)
-		if err != nil {
-			return nil, fmt.Errorf("error processing effect tags for shard ID %s: %v", id, err)
-		}
-
-		if err := validateSpecialFuses(data.SpecialFuses); err != nil {
-			return nil, fmt.Errorf("error validating special fuses for shard ID %s: %v", id, err)
-		}
-
-		rarityShards[rarity] = append(rarityShards[rarity], id)
-		categoryShards[category] = append(categoryShards[category], id)
-		skillShards[data.Skill] = append(skillShards[data.Skill], id)
-		for tag := range effectTags {
+	for id, shard := range shards {
+		rarityShards[shard.Rarity] = append(rarityShards[shard.Rarity], id)
+		categoryShards[shard.Category] = append(categoryShards[shard.Category], id)
+		skillShards[shard.Skill] = append(skillShards[shard.Skill], id)
+		for tag := range shard.EffectTags {
 			tagShards[tag] = append(tagShards[tag], id)
 		}
-		for family := range families {
-			familyShards[family] = append(familyShards[family], id)
-		}
-
-		sources := data.Sources
-		if len(sources) == 0 {
-			sources = []source{
-				{SourceType: "fusionOnly", SourceDesc: "Fusion Only"},
+		for _, stat := range shard.Stats {
+			if !slices.Contains(statShards[stat.Stat], id) {
+				statShards[stat.Stat] = append(statShards[stat.Stat], id)
 			}
 		}
-		for _, source := range sources {
-			sourceTypeShards[source.SourceType] = append(sourceTypeShards[source.SourceType], id)
-		}
-
-		specialFusesDesc := make([][]string, 0, len(data.SpecialFuses))
-		for _, sf := range data.SpecialFuses {
-			desc1 := getRequirementDescription(&sf.Requirement1)
-			desc2 := getRequirementDescription(&sf.Requirement2)
-			desc := []string{desc1, desc2}
-			specialFusesDesc = append(specialFusesDesc, desc)
-		}
-
-		shard := &shard{
-			ID:                id,
-			BazaarId:          data.BazaarId,
-			Name:              data.Name,
-			Rarity:            rarity,
-			Number:            number,
-			AttributeName:     data.AttributeName,
-			EffectDescription: data.EffectDescription,
-			EffectMax:         data.EffectMax,
-			Effect2Max:        data.Effect2Max,
-			EffectTags:        effectTags,
-			Category:          category,
-			Skill:             data.Skill,
-			Families:          families,
-			IsBasicFuseTarget: data.IsBasicFuseTarget,
-			Sources:           sources,
-			SpecialFuses:      data.SpecialFuses,
-			SpecialFusesDesc:  specialFusesDesc,
-
-			// The rest get filled in later
-			FuseCombinations: make(map[string]fuseCombination),
-			ChameleonTargets: make([]string, 0, 3),
+		for family := range shard.Families {
+			familyShards[family] = append(familyShards[family], id)
 		}
-
-		for _, family := range data.Families {
-			shard.Families[family] = true
+		for _, source := range shard.Sources {
+			sourceTypeShards[source.SourceType] = append(sourceTypeShards[source.SourceType], id)
 		}
-
-		shards[id] = shard
 	}
 
-	addBasicFusionTargets(shards)
-	addChameleonFusionTargets(shards)
-	addFuseCombos(shards, config)
-
-	requirementInfo := collectRequirementInfo(shards)
 	requirementList := make([]string, 0, len(requirementInfo))
 	for req := range requirementInfo {
 		requirementList = append(requirementList, req)
 	}
 	slices.SortFunc(requirementList, func(a, b string) int {
-		return len(requirementInfo[b].Targets) - len(requirementInfo[a].Targets)
+		return cmp.Or(len(requirementInfo[b].Targets)-len(requirementInfo[a].Targets), cmp.Compare(a, b))
 	})
 
 	for _, shardIds := range familyShards {
@@ -218,87 +306,34 @@ func processShards(filePath string) (*processedShardData, error) {
 			return getShardSortValue(shards[a]) - getShardSortValue(shards[b])
 		})
 	}
+	for _, shardIds := range statShards {
+		slices.SortFunc(shardIds, func(a, b string) int {
+			return getShardSortValue(shards[a]) - getShardSortValue(shards[b])
+		})
+	}
 	for _, shardIds := range sourceTypeShards {
 		slices.SortFunc(shardIds, func(a, b string) int {
 			return getShardSortValue(shards[a]) - getShardSortValue(shards[b])
 		})
 	}
 
-	shardData := &processedShardData{
+	return &ProcessedShardData{
+		Rarities:               scheme.rarities,
+		Categories:             scheme.categories,
 		FamilyShards:           familyShards,
 		CategoryShards:         categoryShards,
 		SkillShards:            skillShards,
 		RarityShards:           rarityShards,
 		TagShards:              tagShards,
+		StatShards:             statShards,
 		SourceTypeShards:       sourceTypeShards,
 		CostToMax:              config.CostToMax,
+		LevelCosts:             scheme.levelCosts(config.CostToMax),
 		Shards:                 shards,
 		SpecialRequirementInfo: requirementInfo,
 		SpecialRequirements:    requirementList,
-	}
-
-	return shardData, nil
-}
-
-func processId(id string) (rarity, int, error) {
-	var rarity rarity
-	var number int
-
-	if len(id) < 2 {
-		return "", 0, fmt.Errorf("invalid shard ID: %s", id)
-	}
-
-	rarityIndicator := id[0]
-	switch rarityIndicator {
-	case 'C':
-		rarity = rarityCommon
-	case 'U':
-		rarity = rarityUncommon
-	case 'R':
-		rarity = rarityRare
-	case 'E':
-		rarity = rarityEpic
-	case 'L':
-		rarity = rarityLegendary
-	default:
-		return "", 0, fmt.Errorf("unknown rarity in shard ID: %s", id)
-	}
-
-	number, err := strconv.Atoi(id[1:])
-	if err != nil {
-		return "", 0, fmt.Errorf("invalid number in shard ID %s: %v", id, err)
-	}
-
-	return rarity, number, nil
-}
 
-func validateRarity(r string) (rarity, error) {
-	switch r {
-	case "common":
-		return rarityCommon, nil
-	case "uncommon":
-		return rarityUncommon, nil
-	case "rare":
-		return rarityRare, nil
-	case "epic":
-		return rarityEpic, nil
-	case "legendary":
-		return rarityLegendary, nil
-	default:
-		return "", fmt.Errorf("invalid rarity: %s", r)
-	}
-}
-
-func validateCategory(c string) (category, error) {
-	switch c {
-	case "forest":
-		return categoryForest, nil
-	case "water":
-		return categoryWater, nil
-	case "combat":
-		return categoryCombat, nil
-	default:
-		return "", fmt.Errorf("invalid category: %s", c)
+		scheme: scheme,
 	}
 }
 
@@ -306,7 +341,7 @@ func processFamilies(families []string, familyConfig []string) (map[string]bool,
 	familyMap := make(map[string]bool)
 	for _, family := range families {
 		if validateFamily(family, familyConfig) != nil {
-			return nil, fmt.Errorf("invalid family: %s", family)
+			return nil, errorOf(ErrInvalidShardData, "invalid family: %s", family)
 		}
 		familyMap[family] = true
 	}
@@ -317,14 +352,14 @@ func validateFamily(family string, familyConfig []string) error {
 	if slices.Contains(familyConfig, family) {
 		return nil
 	}
-	return fmt.Errorf("invalid family: %s", family)
+	return errorOf(ErrInvalidShardData, "invalid family: %s", family)
 }
 
 func processTags(tags []string, tagConfig []string) (map[string]bool, error) {
 	tagMap := make(map[string]bool)
 	for _, tag := range tags {
 		if validateTag(tag, tagConfig) != nil {
-			return nil, fmt.Errorf("invalid tag: %s", tag)
+			return nil, errorOf(ErrInvalidShardData, "invalid tag: %s", tag)
 		}
 		tagMap[tag] = true
 	}
@@ -335,40 +370,64 @@ func validateTag(tag string, tagConfig []string) error {
 	if slices.Contains(tagConfig, tag) {
 		return nil
 	}
-	return fmt.Errorf("invalid tag: %s", tag)
+	return errorOf(ErrInvalidShardData, "invalid tag: %s", tag)
 }
 
-func validateSpecialFuses(specialFuses []specialFuse) error {
+func validateSpecialFuses(specialFuses []specialFuse, config *shardConfig, scheme *shardScheme) error {
 	for _, sf := range specialFuses {
-		if err := validateSpecialFuseRequirement(sf.Requirement1); err != nil {
-			return fmt.Errorf("invalid special fuse requirement: %v", err)
+		if err := validateSpecialFuseRequirement(sf.Requirement1, config, scheme); err != nil {
+			return fmt.Errorf("invalid special fuse requirement: %w", err)
 		}
-		if err := validateSpecialFuseRequirement(sf.Requirement2); err != nil {
-			return fmt.Errorf("invalid special fuse requirement: %v", err)
+		if err := validateSpecialFuseRequirement(sf.Requirement2, config, scheme); err != nil {
+			return fmt.Errorf("invalid special fuse requirement: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func validateSpecialFuseRequirement(req specialFuseRequirement) error {
-	if len(req.Rarity) == 0 && len(req.Category) == 0 && len(req.Shard) == 0 && len(req.Family) == 0 {
-		return fmt.Errorf("special fuse requirement must have at least one condition")
+func validateSpecialFuseRequirement(req specialFuseRequirement, config *shardConfig, scheme *shardScheme) error {
+	expr, err := req.parsed()
+	if err != nil {
+		return err
 	}
-	for _, r := range req.Rarity {
-		baseRarity := r
-		if r[len(r)-1:] == "+" {
-			baseRarity = r[:len(r)-1]
-		}
-		if _, err := validateRarity(baseRarity); err != nil {
-			return fmt.Errorf("invalid rarity in special fuse requirement: %s", baseRarity)
-		}
+	if expr.op == reqAnd && len(expr.args) == 0 {
+		return errorOf(ErrInvalidRequirement, "special fuse requirement must have at least one condition")
 	}
-	for _, c := range req.Category {
-		if _, err := validateCategory(c); err != nil {
-			return fmt.Errorf("invalid category in special fuse requirement: %s", c)
+	for term := range expr.terms {
+		for _, value := range term.values {
+			err := validateRequirementValue(term.field, value, config, scheme)
+			if err == nil {
+				continue
+			}
+			if term.column > 0 {
+				return &RequirementError{Expr: req.Expr, Column: term.column, Msg: err.Error(), Err: err}
+			}
+			return err
 		}
 	}
+	return nil
+}
 
+func validateRequirementValue(field string, value string, config *shardConfig, scheme *shardScheme) error {
+	switch field {
+	case "rarity":
+		baseRarity := strings.TrimSuffix(value, "+")
+		if _, err := scheme.parseRarity(baseRarity); err != nil {
+			return errorOf(ErrInvalidRequirement, "invalid rarity in special fuse requirement: %s", baseRarity)
+		}
+	case "category":
+		if _, err := scheme.parseCategory(value); err != nil {
+			return errorOf(ErrInvalidRequirement, "invalid category in special fuse requirement: %s", value)
+		}
+	case "shard":
+		if _, exists := config.Shards[value]; !exists {
+			return errorOf(ErrInvalidRequirement, "%w in special fuse requirement: %s", ErrUnknownShard, value)
+		}
+	case "family":
+		if !slices.Contains(config.Families, value) {
+			return errorOf(ErrInvalidRequirement, "invalid family in special fuse requirement: %s", value)
+		}
+	}
 	return nil
 }
@@ -1,18 +1,20 @@
 package shards
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 )
 
 const testShardDataLocation = "../../data/shards.json"
 
-var confirmedResults = []fuseCombination{
+var confirmedResults = []FuseCombination{
 	{
 		Shard1: "R53",
 		Cost1:  2,
 		Shard2: "C19",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "R56", Multiplier: 1},
 			{Type: "basic", ID: "C25", Multiplier: 1},
 			{Type: "special", ID: "R58", Multiplier: 2},
@@ -23,7 +25,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "C19",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "R18", Multiplier: 1},
 			{Type: "basic", ID: "C25", Multiplier: 1},
 			{Type: "special", ID: "R58", Multiplier: 2},
@@ -34,7 +36,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "U11",
 		Cost2:  2,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "R18", Multiplier: 1},
 			{Type: "basic", ID: "U20", Multiplier: 1},
 			{Type: "special", ID: "U2", Multiplier: 2},
@@ -45,7 +47,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "C9",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "R18", Multiplier: 1},
 			{Type: "special", ID: "R15", Multiplier: 2},
 			{Type: "special", ID: "C3", Multiplier: 2},
@@ -56,7 +58,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "R6",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "R18", Multiplier: 1},
 			{Type: "special", ID: "R15", Multiplier: 2},
 			{Type: "special", ID: "C3", Multiplier: 2},
@@ -67,7 +69,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "R61",
 		Cost2:  2,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "special", ID: "R58", Multiplier: 2},
 			{Type: "special", ID: "U34", Multiplier: 2},
 			{Type: "special", ID: "C1", Multiplier: 2},
@@ -78,7 +80,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "E26",
 		Cost2:  2,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "C25", Multiplier: 1},
 			{Type: "special", ID: "E28", Multiplier: 2},
 			{Type: "special", ID: "R58", Multiplier: 2},
@@ -89,7 +91,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  2,
 		Shard2: "C10",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "C25", Multiplier: 1},
 			{Type: "special", ID: "U9", Multiplier: 2},
 			{Type: "special", ID: "C1", Multiplier: 2},
@@ -100,7 +102,7 @@ var confirmedResults = []fuseCombination{
 		Cost1:  5,
 		Shard2: "C27",
 		Cost2:  5,
-		Results: []fuseResult{
+		Results: []FuseResult{
 			{Type: "basic", ID: "C14", Multiplier: 1},
 			{Type: "basic", ID: "C29", Multiplier: 1},
 			{Type: "special", ID: "U5", Multiplier: 1},
@@ -109,7 +111,7 @@ var confirmedResults = []fuseCombination{
 }
 
 func TestProcessShardConfig(t *testing.T) {
-	shardData, err := processShards(testShardDataLocation)
+	shardData, err := ProcessShards(testShardDataLocation)
 
 	// fmt.Printf("Shard: %v\n", shards["C1"])
 
@@ -164,3 +166,70 @@ func TestProcessShardConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestParallelFuseCombosMatchSerial(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	serial, err := processShardConfigWithOptions(cloneConfig(t, config), ProcessOptions{Workers: 1})
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	expected := marshalProcessed(t, serial)
+	for _, workers := range []int{0, 2, 7, 1000} {
+		parallel, err := processShardConfigWithOptions(cloneConfig(t, config), ProcessOptions{Workers: workers})
+		if err != nil {
+			t.Fatalf("Failed to process shard config with %d workers: %v", workers, err)
+		}
+		if !bytes.Equal(marshalProcessed(t, parallel), expected) {
+			t.Errorf("Output with %d workers differs from the serial output", workers)
+		}
+	}
+}
+
+// scaledConfig repeats every shard copies times, each copy numbered 100 past the last, so the
+// benchmarks can see how processing grows with the shard list
+func scaledConfig(b *testing.B, copies int) *shardConfig {
+	b.Helper()
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		b.Fatalf("Failed to load shard config: %v", err)
+	}
+	scheme, err := newShardScheme(config)
+	if err != nil {
+		b.Fatalf("Failed to read shard scheme: %v", err)
+	}
+	originals := make(map[string]shardConfigData, len(config.Shards))
+	for id, data := range config.Shards {
+		originals[id] = data
+	}
+	for k := 1; k < copies; k++ {
+		for id, data := range originals {
+			r, number, err := scheme.parseID(id)
+			if err != nil {
+				b.Fatalf("Failed to parse shard ID %s: %v", id, err)
+			}
+			data.Name = fmt.Sprintf("%s %d", data.Name, k)
+			data.BazaarId = fmt.Sprintf("%s_%d", data.BazaarId, k)
+			data.Aliases = nil
+			config.Shards[scheme.shardID(r, number+100*k)] = data
+		}
+	}
+	return config
+}
+
+func BenchmarkProcessShards(b *testing.B) {
+	for _, copies := range []int{1, 2, 4} {
+		config := scaledConfig(b, copies)
+		for _, workers := range []int{1, 0} {
+			b.Run(fmt.Sprintf("shards=%d/workers=%d", len(config.Shards), workers), func(b *testing.B) {
+				for b.Loop() {
+					if _, err := processShardConfigWithOptions(config, ProcessOptions{Workers: workers}); err != nil {
+						b.Fatalf("Failed to process shard config: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
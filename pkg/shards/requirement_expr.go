@@ -0,0 +1,457 @@
+package shards
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Requirement expressions combine field matches with ! (not), & (and) and | (or), in that order of
+// precedence, plus parentheses:
+//
+//	category:forest & rarity:common
+//	family:shulker | shard:R6
+//	rarity:uncommon+ & !family:elemental,reptile
+//
+// A term is field:value, where field is rarity, category, family or shard. A comma-separated
+// list of values matches any of them, and a rarity ending in + matches that rarity or higher.
+
+type reqOp int
+
+const (
+	reqTerm reqOp = iota
+	reqNot
+	reqAnd
+	reqOr
+)
+
+type reqNode struct {
+	op reqOp
+	// Terms only
+	field  string
+	values []string
+	// Column of the term in the source expression, 0 for requirements written as objects
+	column int
+	args   []*reqNode
+}
+
+// Terms are ordered like the fields of the object form, so equivalent requirements written
+// either way describe themselves identically
+var reqFields = []string{"shard", "family", "category", "rarity"}
+
+var reqFieldLabels = map[string]string{
+	"shard":    "Shard",
+	"family":   "Family",
+	"category": "Category",
+	"rarity":   "Rarity",
+}
+
+// RequirementError points at the part of a requirement expression that is invalid
+type RequirementError struct {
+	Expr   string
+	Column int
+	Msg    string
+	// The validation error behind Msg, if any
+	Err error
+}
+
+func (e *RequirementError) Error() string {
+	return fmt.Sprintf("%s at column %d in %q", e.Msg, e.Column, e.Expr)
+}
+
+func (e *RequirementError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrInvalidRequirement, e.Err}
+	}
+	return []error{ErrInvalidRequirement}
+}
+
+func (r *specialFuseRequirement) UnmarshalJSON(data []byte) error {
+	*r = specialFuseRequirement{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		if err := json.Unmarshal(trimmed, &r.Expr); err != nil {
+			return err
+		}
+	} else {
+		type requirementFields specialFuseRequirement
+		if err := json.Unmarshal(data, (*requirementFields)(r)); err != nil {
+			return err
+		}
+	}
+	// Syntax errors are kept until validation, which knows which shard they belong to
+	r.expr, r.parseErr = r.parse()
+	return nil
+}
+
+func (r specialFuseRequirement) MarshalJSON() ([]byte, error) {
+	if r.Expr != "" {
+		// Keep & readable in files people edit; callers that escape HTML still will
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(r.Expr); err != nil {
+			return nil, err
+		}
+		return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+	}
+	type requirementFields specialFuseRequirement
+	return json.Marshal(requirementFields(r))
+}
+
+func (r *specialFuseRequirement) parse() (*reqNode, error) {
+	if r.Expr != "" {
+		return parseRequirement(r.Expr)
+	}
+	terms := make([]*reqNode, 0, 4)
+	for _, field := range reqFields {
+		var values []string
+		switch field {
+		case "shard":
+			values = r.Shard
+		case "family":
+			values = r.Family
+		case "category":
+			values = r.Category
+		case "rarity":
+			values = r.Rarity
+		}
+		if len(values) > 0 {
+			terms = append(terms, &reqNode{op: reqTerm, field: field, values: values})
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &reqNode{op: reqAnd, args: terms}, nil
+}
+
+// parsed returns the requirement parsed when it was unmarshaled. Requirements built in code are
+// parsed on demand.
+func (r *specialFuseRequirement) parsed() (*reqNode, error) {
+	if r.expr != nil || r.parseErr != nil {
+		return r.expr, r.parseErr
+	}
+	return r.parse()
+}
+
+// expression is the parsed requirement, or nil if it doesn't parse
+func (r *specialFuseRequirement) expression() *reqNode {
+	expr, _ := r.parsed()
+	return expr
+}
+
+func parseRequirement(src string) (*reqNode, error) {
+	p := &reqParser{src: src}
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("empty requirement")
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return normalizeRequirement(node), nil
+}
+
+type reqParser struct {
+	src string
+	pos int
+}
+
+func (p *reqParser) errorf(format string, args ...any) error {
+	return &RequirementError{Expr: p.src, Column: p.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *reqParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *reqParser) peek(c byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.src) && p.src[p.pos] == c
+}
+
+func (p *reqParser) describeNext() string {
+	if p.pos >= len(p.src) {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", p.src[p.pos])
+}
+
+func (p *reqParser) parseOr() (*reqNode, error) {
+	return p.parseBinary(reqOr, '|', p.parseAnd)
+}
+
+func (p *reqParser) parseAnd() (*reqNode, error) {
+	return p.parseBinary(reqAnd, '&', p.parseUnary)
+}
+
+func (p *reqParser) parseBinary(op reqOp, sep byte, operand func() (*reqNode, error)) (*reqNode, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	args := []*reqNode{first}
+	for p.peek(sep) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, next)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return &reqNode{op: op, args: args}, nil
+}
+
+func (p *reqParser) parseUnary() (*reqNode, error) {
+	switch {
+	case p.peek('!'):
+		p.pos++
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &reqNode{op: reqNot, args: []*reqNode{arg}}, nil
+	case p.peek('('):
+		open := p.pos
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(')') {
+			if p.pos >= len(p.src) {
+				p.pos = open
+				return nil, p.errorf("unclosed parenthesis")
+			}
+			return nil, p.errorf("expected ')' but found %s", p.describeNext())
+		}
+		p.pos++
+		return node, nil
+	default:
+		return p.parseTerm()
+	}
+}
+
+func (p *reqParser) parseTerm() (*reqNode, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= 'a' && p.src[p.pos] <= 'z' {
+		p.pos++
+	}
+	field := p.src[start:p.pos]
+	if field == "" {
+		return nil, p.errorf("expected a field name but found %s", p.describeNext())
+	}
+	if _, ok := reqFieldLabels[field]; !ok {
+		p.pos = start
+		return nil, p.errorf("unknown field %q, expected one of %s", field, strings.Join(reqFields, ", "))
+	}
+	if !p.peek(':') {
+		return nil, p.errorf("expected ':' after %s but found %s", field, p.describeNext())
+	}
+	p.pos++
+
+	term := &reqNode{op: reqTerm, field: field, column: start + 1}
+	for {
+		p.skipSpace()
+		valueStart := p.pos
+		for p.pos < len(p.src) && !strings.ContainsRune("&|!(),:", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		value := strings.TrimRight(p.src[valueStart:p.pos], " ")
+		if value == "" {
+			return nil, p.errorf("expected a %s but found %s", field, p.describeNext())
+		}
+		term.values = append(term.values, value)
+		if !p.peek(',') {
+			return term, nil
+		}
+		p.pos++
+	}
+}
+
+// normalizeRequirement flattens nested and/or nodes, merges same-field terms under an or, and
+// orders the terms of an and like the object form. Two spellings of the same requirement then
+// format and describe the same way.
+func normalizeRequirement(n *reqNode) *reqNode {
+	if n.op == reqTerm {
+		return n
+	}
+	args := make([]*reqNode, 0, len(n.args))
+	for _, arg := range n.args {
+		arg = normalizeRequirement(arg)
+		if arg.op == n.op && n.op != reqNot {
+			args = append(args, arg.args...)
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	switch n.op {
+	case reqOr:
+		merged := make([]*reqNode, 0, len(args))
+		for _, arg := range args {
+			i := slices.IndexFunc(merged, func(m *reqNode) bool {
+				return m.op == reqTerm && arg.op == reqTerm && m.field == arg.field
+			})
+			if i < 0 {
+				merged = append(merged, arg)
+				continue
+			}
+			combined := *merged[i]
+			combined.values = append(slices.Clone(combined.values), arg.values...)
+			merged[i] = &combined
+		}
+		args = merged
+		if len(args) == 1 {
+			return args[0]
+		}
+	case reqAnd:
+		slices.SortStableFunc(args, func(a, b *reqNode) int {
+			return cmp.Compare(reqFieldRank(a), reqFieldRank(b))
+		})
+	}
+	return &reqNode{op: n.op, args: args}
+}
+
+func reqFieldRank(n *reqNode) int {
+	if n.op != reqTerm {
+		return len(reqFields)
+	}
+	return slices.Index(reqFields, n.field)
+}
+
+func (n *reqNode) matches(shard *Shard) bool {
+	switch n.op {
+	case reqTerm:
+		return slices.ContainsFunc(n.values, func(value string) bool {
+			return termValueMatches(n.field, value, shard)
+		})
+	case reqNot:
+		return !n.args[0].matches(shard)
+	case reqAnd:
+		for _, arg := range n.args {
+			if !arg.matches(shard) {
+				return false
+			}
+		}
+		return true
+	case reqOr:
+		for _, arg := range n.args {
+			if arg.matches(shard) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func termValueMatches(field string, value string, shard *Shard) bool {
+	switch field {
+	case "rarity":
+		if base, ok := strings.CutSuffix(value, "+"); ok {
+			return shard.scheme.rank(shard.Rarity) >= shard.scheme.rank(rarity(base))
+		}
+		return shard.Rarity == rarity(value)
+	case "category":
+		return shard.Category == category(value)
+	case "shard":
+		return shard.ID == value
+	case "family":
+		return shard.Families[value]
+	}
+	return false
+}
+
+// terms visits every term in the requirement
+func (n *reqNode) terms(yield func(*reqNode) bool) {
+	n.walkTerms(yield)
+}
+
+func (n *reqNode) walkTerms(yield func(*reqNode) bool) bool {
+	if n.op == reqTerm {
+		return yield(n)
+	}
+	for _, arg := range n.args {
+		if !arg.walkTerms(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// format writes the canonical expression text
+func (n *reqNode) format() string {
+	switch n.op {
+	case reqTerm:
+		return n.field + ":" + strings.Join(n.values, ",")
+	case reqNot:
+		return "!" + n.args[0].formatWrapped(reqNot)
+	}
+	sep := " & "
+	if n.op == reqOr {
+		sep = " | "
+	}
+	parts := make([]string, len(n.args))
+	for i, arg := range n.args {
+		parts[i] = arg.formatWrapped(n.op)
+	}
+	return strings.Join(parts, sep)
+}
+
+// formatWrapped adds parentheses where the child binds more loosely than its parent
+func (n *reqNode) formatWrapped(parent reqOp) string {
+	if n.op != reqTerm && n.op != reqNot && n.op > parent {
+		return "(" + n.format() + ")"
+	}
+	return n.format()
+}
+
+// describe renders the requirement for people. It is also the key that identifies requirements,
+// so its output for object-form requirements must stay as it has always been.
+func (n *reqNode) describe() string {
+	switch n.op {
+	case reqTerm:
+		return reqFieldLabels[n.field] + ": " + strings.Join(n.values, " or ")
+	case reqNot:
+		return "not " + n.args[0].describeWrapped()
+	}
+	sep := " and "
+	if n.op == reqOr {
+		sep = " or "
+	}
+	parts := make([]string, len(n.args))
+	for i, arg := range n.args {
+		parts[i] = arg.describeWrapped()
+	}
+	return strings.Join(parts, sep)
+}
+
+func (n *reqNode) describeWrapped() string {
+	if n.op == reqAnd || n.op == reqOr {
+		return "(" + n.describe() + ")"
+	}
+	return n.describe()
+}
+
+// FormatRequirement parses a requirement expression and returns it in canonical form
+func FormatRequirement(expr string) (string, error) {
+	node, err := parseRequirement(expr)
+	if err != nil {
+		return "", err
+	}
+	return node.format(), nil
+}
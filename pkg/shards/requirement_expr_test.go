@@ -0,0 +1,156 @@
+package shards
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFormatRequirement(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"category:forest & rarity:common", "category:forest & rarity:common"},
+		{"rarity:common&category:forest", "category:forest & rarity:common"},
+		{"family:shulker | shard:R6", "family:shulker | shard:R6"},
+		{"rarity:uncommon+", "rarity:uncommon+"},
+		{"rarity:rare | rarity:epic", "rarity:rare,epic"},
+		{"family: tropical fish , squid", "family:tropical fish,squid"},
+		{"!family:elemental & rarity:rare+", "rarity:rare+ & !family:elemental"},
+		{"!(category:water | category:combat)", "!category:water,combat"},
+		{"(family:bird & rarity:rare) | shard:R6", "family:bird & rarity:rare | shard:R6"},
+		{"rarity:rare & (family:bird | category:water)", "rarity:rare & (family:bird | category:water)"},
+		{"!(family:bird & rarity:rare)", "!(family:bird & rarity:rare)"},
+	}
+	for _, tt := range tests {
+		got, err := FormatRequirement(tt.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.expr, tt.expected, got)
+		}
+		again, err := FormatRequirement(got)
+		if err != nil || again != got {
+			t.Errorf("%q: canonical form does not round-trip, got %q, %v", got, again, err)
+		}
+	}
+}
+
+func TestRequirementSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{"", 1},
+		{"category forest", 10},
+		{"color:red", 1},
+		{"category:forest &", 18},
+		{"category:forest & (rarity:rare", 19},
+		{"rarity:rare)", 12},
+		{"family:", 8},
+		{"category:forest rarity:rare", 23},
+	}
+	for _, tt := range tests {
+		_, err := FormatRequirement(tt.expr)
+		var reqErr *RequirementError
+		if !errors.As(err, &reqErr) {
+			t.Errorf("%q: expected a RequirementError, got %v", tt.expr, err)
+			continue
+		}
+		if reqErr.Column != tt.column {
+			t.Errorf("%q: expected column %d, got %d (%v)", tt.expr, tt.column, reqErr.Column, err)
+		}
+		if !errors.Is(err, ErrInvalidRequirement) {
+			t.Errorf("%q: error does not match ErrInvalidRequirement", tt.expr)
+		}
+	}
+}
+
+func TestRequirementForms(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	shardData, err := processShardConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	matcher := newRequirementMatcher(getSortedShards(shardData.Shards))
+
+	tests := []struct {
+		object string
+		expr   string
+	}{
+		{`{"category":["forest"],"rarity":["common"]}`, `"rarity:common & category:forest"`},
+		{`{"family":["shulker"]}`, `"family:shulker"`},
+		{`{"rarity":["uncommon+"]}`, `"rarity:uncommon+"`},
+		{`{"shard":["R6","E8"],"rarity":["rare"]}`, `"(shard:R6 | shard:E8) & rarity:rare"`},
+	}
+	for _, tt := range tests {
+		var object, expr specialFuseRequirement
+		if err := json.Unmarshal([]byte(tt.object), &object); err != nil {
+			t.Fatalf("%s: %v", tt.object, err)
+		}
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if getRequirementDescription(&object) != getRequirementDescription(&expr) {
+			t.Errorf("%s and %s describe differently: %q vs %q", tt.object, tt.expr,
+				getRequirementDescription(&object), getRequirementDescription(&expr))
+		}
+		objectMatches := matcher.matches(matcher.compile(&object))
+		exprMatches := matcher.matches(matcher.compile(&expr))
+		if len(objectMatches) == 0 || len(objectMatches) != len(exprMatches) {
+			t.Errorf("%s and %s match different shards", tt.object, tt.expr)
+		}
+
+		out, err := json.Marshal(expr)
+		var written string
+		if err != nil || json.Unmarshal(out, &written) != nil || `"`+written+`"` != tt.expr {
+			t.Errorf("Expression form must marshal back as written, got %s", out)
+		}
+	}
+
+	// Negation has no object form; check the bitset compilation against direct evaluation
+	for _, src := range []string{"!family:elemental", "!(category:forest | rarity:rare+) & !shard:C1", "!!rarity:common"} {
+		req := specialFuseRequirement{Expr: src}
+		compiled := matcher.compile(&req)
+		count := 0
+		for i, s := range matcher.shards {
+			if compiled.has(i) != meetsSpecialFuseRequirement(s, &req) {
+				t.Errorf("%q: compiled and evaluated requirement disagree on %s", src, s.ID)
+			}
+			if compiled.has(i) {
+				count++
+			}
+		}
+		if count == 0 || count == len(matcher.shards) {
+			t.Errorf("%q: expected some but not all shards to match, got %d", src, count)
+		}
+	}
+}
+
+func TestRequirementValidation(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	edited := config.Shards["C1"]
+	edited.SpecialFuses = []specialFuse{{
+		Requirement1: specialFuseRequirement{Expr: "category:forest & shard:X99"},
+		Requirement2: specialFuseRequirement{Expr: "rarity:rare+"},
+	}}
+	config.Shards["C1"] = edited
+
+	_, err = processShardConfig(config)
+	var reqErr *RequirementError
+	if !errors.As(err, &reqErr) || reqErr.Column != 19 {
+		t.Fatalf("Expected an error at column 19, got %v", err)
+	}
+	if !errors.Is(err, ErrUnknownShard) || !errors.Is(err, ErrInvalidRequirement) {
+		t.Errorf("Expected unknown shard and invalid requirement, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package shards
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// shardBitset has one bit per shard, indexed by position in the sorted shard list
+type shardBitset []uint64
+
+func newShardBitset(n int) shardBitset {
+	return make(shardBitset, (n+63)/64)
+}
+
+func (b shardBitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b shardBitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b shardBitset) and(other shardBitset) {
+	for i := range b {
+		b[i] &= other[i]
+	}
+}
+
+func (b shardBitset) or(other shardBitset) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+// not flips the first n bits and keeps the rest clear
+func (b shardBitset) not(n int) {
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if n%64 != 0 {
+		b[len(b)-1] &= 1<<(n%64) - 1
+	}
+}
+
+func (b shardBitset) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// requirementMatcher compiles special fuse requirements into bitsets once, so checking whether
+// a shard meets one is a single bit lookup instead of re-parsing rarities and scanning lists.
+// It is only valid for the shard attributes it was built from.
+type requirementMatcher struct {
+	shards       []*Shard
+	index        map[string]int
+	rarityBits   map[rarity]shardBitset
+	categoryBits map[category]shardBitset
+	familyBits   map[string]shardBitset
+	scheme       *shardScheme
+}
+
+func newRequirementMatcher(sortedShards []*Shard) *requirementMatcher {
+	m := &requirementMatcher{
+		shards:       sortedShards,
+		index:        make(map[string]int, len(sortedShards)),
+		rarityBits:   make(map[rarity]shardBitset),
+		categoryBits: make(map[category]shardBitset),
+		familyBits:   make(map[string]shardBitset),
+	}
+	for i, s := range sortedShards {
+		m.index[s.ID] = i
+		m.scheme = s.scheme
+		bitsForKey(m, m.rarityBits, s.Rarity).set(i)
+		bitsForKey(m, m.categoryBits, s.Category).set(i)
+		for family := range s.Families {
+			bitsForKey(m, m.familyBits, family).set(i)
+		}
+	}
+	return m
+}
+
+func bitsForKey[K comparable](m *requirementMatcher, sets map[K]shardBitset, key K) shardBitset {
+	if _, exists := sets[key]; !exists {
+		sets[key] = newShardBitset(len(m.shards))
+	}
+	return sets[key]
+}
+
+func (m *requirementMatcher) compile(req *specialFuseRequirement) shardBitset {
+	expr := req.expression()
+	if expr == nil {
+		return newShardBitset(len(m.shards))
+	}
+	return m.compileNode(expr)
+}
+
+func (m *requirementMatcher) compileNode(n *reqNode) shardBitset {
+	result := newShardBitset(len(m.shards))
+	switch n.op {
+	case reqTerm:
+		for _, value := range n.values {
+			result.or(m.termBits(n.field, value))
+		}
+	case reqNot:
+		result.or(m.compileNode(n.args[0]))
+		result.not(len(m.shards))
+	case reqAnd:
+		result.not(len(m.shards))
+		for _, arg := range n.args {
+			result.and(m.compileNode(arg))
+		}
+	case reqOr:
+		for _, arg := range n.args {
+			result.or(m.compileNode(arg))
+		}
+	}
+	return result
+}
+
+func (m *requirementMatcher) termBits(field string, value string) shardBitset {
+	var b shardBitset
+	switch field {
+	case "rarity":
+		base, orHigher := strings.CutSuffix(value, "+")
+		if !orHigher {
+			b = m.rarityBits[rarity(base)]
+			break
+		}
+		matching := newShardBitset(len(m.shards))
+		minValue := m.scheme.rank(rarity(base))
+		for shardRarity, b := range m.rarityBits {
+			if m.scheme.rank(shardRarity) >= minValue {
+				matching.or(b)
+			}
+		}
+		return matching
+	case "category":
+		b = m.categoryBits[category(value)]
+	case "family":
+		b = m.familyBits[value]
+	case "shard":
+		matching := newShardBitset(len(m.shards))
+		if i, exists := m.index[value]; exists {
+			matching.set(i)
+		}
+		return matching
+	}
+	// Values no shard has, like a family nobody is in yet, match nothing
+	if b == nil {
+		return newShardBitset(len(m.shards))
+	}
+	return b
+}
+
+// matches lists the shards in a compiled requirement, in sorted shard order
+func (m *requirementMatcher) matches(b shardBitset) []string {
+	ids := make([]string, 0, b.count())
+	for i, s := range m.shards {
+		if b.has(i) {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
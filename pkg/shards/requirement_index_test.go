@@ -0,0 +1,100 @@
+package shards
+
+import (
+	"reflect"
+	"testing"
+)
+
+// directSpecialResults evaluates every special fuse requirement against the shards directly,
+// as the reference the compiled bitsets have to agree with
+func directSpecialResults(results []FuseResult, s1 *Shard, s2 *Shard, allSpecialFuses []*specialFuseOption, cfg *shardConfig) []FuseResult {
+	for _, opt := range allSpecialFuses {
+		dir1 := meetsSpecialFuseRequirement(s1, &opt.option.Requirement1) && meetsSpecialFuseRequirement(s2, &opt.option.Requirement2)
+		dir2 := meetsSpecialFuseRequirement(s2, &opt.option.Requirement1) && meetsSpecialFuseRequirement(s1, &opt.option.Requirement2)
+		if dir1 || dir2 {
+			mult := 1
+			if opt.option.IsBoosted {
+				mult = cfg.SpecialFuseMultiplier
+			}
+			results = append(results, FuseResult{
+				Type:       "special",
+				ID:         opt.target,
+				Multiplier: mult,
+			})
+		}
+	}
+	return results
+}
+
+func loadTestCombiner(tb testing.TB) *fuseCombiner {
+	tb.Helper()
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		tb.Fatalf("Failed to load shard config: %v", err)
+	}
+	shardData, err := processShardConfig(config)
+	if err != nil {
+		tb.Fatalf("Failed to process shard config: %v", err)
+	}
+	return newFuseCombiner(shardData.Shards, config)
+}
+
+func TestCompiledRequirementsMatchDirect(t *testing.T) {
+	combiner := loadTestCombiner(t)
+
+	for _, opt := range combiner.allSpecialFuses {
+		for i, s := range combiner.matcher.shards {
+			if opt.req1.has(i) != meetsSpecialFuseRequirement(s, &opt.option.Requirement1) {
+				t.Errorf("Requirement 1 of fuse into %s disagrees on %s", opt.target, s.ID)
+			}
+			if opt.req2.has(i) != meetsSpecialFuseRequirement(s, &opt.option.Requirement2) {
+				t.Errorf("Requirement 2 of fuse into %s disagrees on %s", opt.target, s.ID)
+			}
+		}
+	}
+
+	for _, s1 := range combiner.matcher.shards {
+		for _, s2 := range combiner.matcher.shards {
+			compiled := combiner.appendSpecialResults(nil, s1, s2)
+			direct := directSpecialResults(nil, s1, s2, combiner.allSpecialFuses, combiner.cfg)
+			if !reflect.DeepEqual(compiled, direct) {
+				t.Fatalf("Special results for %s+%s differ: compiled %v, direct %v", s1.ID, s2.ID, compiled, direct)
+			}
+		}
+	}
+}
+
+func BenchmarkSpecialResultsCompiled(b *testing.B) {
+	combiner := loadTestCombiner(b)
+	results := make([]FuseResult, 0, 32)
+	for b.Loop() {
+		for _, s1 := range combiner.matcher.shards {
+			for _, s2 := range combiner.matcher.shards {
+				results = combiner.appendSpecialResults(results[:0], s1, s2)
+			}
+		}
+	}
+}
+
+func BenchmarkSpecialResultsDirect(b *testing.B) {
+	combiner := loadTestCombiner(b)
+	results := make([]FuseResult, 0, 32)
+	for b.Loop() {
+		for _, s1 := range combiner.matcher.shards {
+			for _, s2 := range combiner.matcher.shards {
+				results = directSpecialResults(results[:0], s1, s2, combiner.allSpecialFuses, combiner.cfg)
+			}
+		}
+	}
+}
+
+func BenchmarkCompileRequirements(b *testing.B) {
+	combiner := loadTestCombiner(b)
+	shards := make(map[string]*Shard, len(combiner.matcher.shards))
+	for _, s := range combiner.matcher.shards {
+		shards[s.ID] = s
+	}
+	for b.Loop() {
+		newFuseCombiner(shards, combiner.cfg)
+	}
+}
@@ -0,0 +1,235 @@
+package shards
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+type matchKind int
+
+const (
+	matchExact matchKind = iota
+	matchPrefix
+	matchSubstring
+	matchFuzzy
+)
+
+type ShardMatch struct {
+	Shard *Shard
+	// Field is which key matched: "id", "name", "attributeName", "bazaarId" or "alias"
+	Field    string
+	Key      string
+	kind     matchKind
+	Distance int
+}
+
+// Resolver maps human input onto shards. It accepts IDs ("R53"), names ("Grove"), attribute
+// names ("Nature Elemental"), bazaar IDs ("SHARD_GROVE") and aliases, ignoring case, spacing and
+// punctuation, and tolerates typos by edit distance.
+type Resolver struct {
+	shards map[string]*Shard
+	keys   []resolverKey
+	// MaxDistance caps the edit distance for typo matches. Shorter queries allow less,
+	// so that "R6" is never corrected to "R8".
+	MaxDistance int
+}
+
+type resolverKey struct {
+	key   string
+	field string
+	shard *Shard
+}
+
+// NewResolver indexes the shards. Aliases in the shard data are always included; extraAliases
+// maps further alias -> shard ID on top of those.
+func NewResolver(shardData *ProcessedShardData, extraAliases map[string]string) (*Resolver, error) {
+	r := &Resolver{
+		shards:      shardData.Shards,
+		keys:        make([]resolverKey, 0, len(shardData.Shards)*5),
+		MaxDistance: 2,
+	}
+	for _, s := range getSortedShards(shardData.Shards) {
+		r.addKey(s.ID, "id", s)
+		r.addKey(s.Name, "name", s)
+		r.addKey(s.AttributeName, "attributeName", s)
+		r.addKey(s.BazaarId, "bazaarId", s)
+		r.addKey(strings.TrimPrefix(s.BazaarId, "SHARD_"), "bazaarId", s)
+		for _, alias := range s.Aliases {
+			r.addKey(alias, "alias", s)
+		}
+	}
+
+	aliasNames := make([]string, 0, len(extraAliases))
+	for alias := range extraAliases {
+		aliasNames = append(aliasNames, alias)
+	}
+	slices.Sort(aliasNames)
+	for _, alias := range aliasNames {
+		s, exists := shardData.Shards[extraAliases[alias]]
+		if !exists {
+			return nil, errorOf(ErrUnknownShard, "alias %q refers to unknown shard %s", alias, extraAliases[alias])
+		}
+		r.addKey(alias, "alias", s)
+	}
+
+	return r, nil
+}
+
+// LoadAliases reads a JSON object of alias -> shard ID, for aliases kept outside the shard data
+func LoadAliases(filePath string) (map[string]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias file: %w", err)
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+func (r *Resolver) addKey(key string, field string, s *Shard) {
+	normalized := normalizeQuery(key)
+	if normalized == "" {
+		return
+	}
+	r.keys = append(r.keys, resolverKey{key: normalized, field: field, shard: s})
+}
+
+// Resolve returns every matching shard, best first. Each shard appears once, with its best match.
+func (r *Resolver) Resolve(query string) []ShardMatch {
+	q := normalizeQuery(query)
+	if q == "" {
+		return nil
+	}
+	maxDistance := min(r.MaxDistance, len(q)/4)
+
+	best := make(map[string]ShardMatch)
+	for _, k := range r.keys {
+		match := ShardMatch{Shard: k.shard, Field: k.field, Key: k.key}
+		switch {
+		case k.key == q:
+			match.kind = matchExact
+		case strings.HasPrefix(k.key, q):
+			match.kind = matchPrefix
+		case strings.Contains(k.key, q):
+			match.kind = matchSubstring
+		default:
+			if maxDistance == 0 {
+				continue
+			}
+			dist := editDistance(q, k.key, maxDistance)
+			if dist > maxDistance {
+				continue
+			}
+			match.kind = matchFuzzy
+			match.Distance = dist
+		}
+
+		if prev, exists := best[k.shard.ID]; !exists || compareMatches(match, prev) < 0 {
+			best[k.shard.ID] = match
+		}
+	}
+
+	matches := make([]ShardMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	slices.SortFunc(matches, compareMatches)
+	return matches
+}
+
+// ResolveOne returns the single best shard for the query, or an error if nothing matches or
+// the best matches are equally good.
+func (r *Resolver) ResolveOne(query string) (*Shard, error) {
+	matches := r.Resolve(query)
+	if len(matches) == 0 {
+		return nil, errorOf(ErrUnknownShard, "no shard matches %q", query)
+	}
+	if len(matches) == 1 || matchRank(matches[0]) < matchRank(matches[1]) {
+		return matches[0].Shard, nil
+	}
+
+	names := make([]string, 0, 5)
+	for _, m := range matches {
+		if matchRank(m) != matchRank(matches[0]) {
+			break
+		}
+		if len(names) == 5 {
+			names = append(names, "...")
+			break
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", m.Shard.Name, m.Shard.ID))
+	}
+	return nil, errorOf(ErrAmbiguousShard, "%q is ambiguous: %s", query, strings.Join(names, ", "))
+}
+
+func matchRank(m ShardMatch) int {
+	return int(m.kind)*100 + m.Distance
+}
+
+func compareMatches(a, b ShardMatch) int {
+	return cmp.Or(
+		cmp.Compare(matchRank(a), matchRank(b)),
+		getShardSortValue(a.Shard)-getShardSortValue(b.Shard),
+	)
+}
+
+// Case, spaces and punctuation are all ignored, so "nature-elemental" finds "Nature Elemental"
+func normalizeQuery(s string) string {
+	var sb strings.Builder
+	for _, c := range strings.ToLower(s) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// Edit distance counting insertions, deletions, substitutions and adjacent transpositions,
+// so "grvoe" is one typo away from "grove". Gives up once two consecutive rows exceed limit,
+// since a transposition can reach back over one row.
+func editDistance(a string, b string, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	prevRowMin := 0
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit && prevRowMin > limit {
+			return limit + 1
+		}
+		prevRowMin = rowMin
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
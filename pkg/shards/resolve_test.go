@@ -0,0 +1,63 @@
+package shards
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	resolver, err := NewResolver(shardData, map[string]string{"chamo": "L4"})
+	if err != nil {
+		t.Fatalf("Failed to build resolver: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"C1", "C1"},
+		{"r53", "R53"},
+		{"Grove", "C1"},
+		{"nature elemental", "C1"},
+		{"SHARD_GROVE", "C1"},
+		{"shard-mist", "C2"},
+		{"grvoe", "C1"},
+		{"Fog Elementl", "C2"},
+		{"chamo", "L4"},
+	}
+	for _, tt := range tests {
+		s, err := resolver.ResolveOne(tt.query)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.query, err)
+			continue
+		}
+		if s.ID != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.query, tt.expected, s.ID)
+		}
+	}
+
+	if _, err := resolver.ResolveOne("xyzzy"); !errors.Is(err, ErrUnknownShard) {
+		t.Errorf("Expected ErrUnknownShard for nonsense query, got %v", err)
+	}
+	if s, err := resolver.ResolveOne("R7"); err == nil && s.ID != "R7" {
+		t.Errorf("Short IDs must not be typo-corrected, got %s", s.ID)
+	}
+
+	matches := resolver.Resolve("elemental")
+	if len(matches) < 2 {
+		t.Fatalf("Expected several elemental matches, got %d", len(matches))
+	}
+	for i := 1; i < len(matches); i++ {
+		if compareMatches(matches[i-1], matches[i]) > 0 {
+			t.Errorf("Matches not ranked: %s before %s", matches[i-1].Shard.ID, matches[i].Shard.ID)
+		}
+	}
+
+	if _, err := NewResolver(shardData, map[string]string{"nope": "Z99"}); err == nil {
+		t.Error("Expected error for alias to unknown shard")
+	}
+}
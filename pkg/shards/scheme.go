@@ -0,0 +1,174 @@
+package shards
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// rarityDef declares a rarity. Rarities are listed lowest first, and shard IDs start with the prefix.
+type rarityDef struct {
+	ID     rarity `json:"id"`
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+	Color  string `json:"color,omitempty"`
+	// Shards needed for each attribute level. Only needed for rarities without a known curve.
+	LevelCosts []int `json:"levelCosts,omitempty"`
+}
+
+type categoryDef struct {
+	ID   category `json:"id"`
+	Name string   `json:"name"`
+}
+
+// Shard data written before rarities and categories were declared gets these
+var (
+	defaultRarities = []rarityDef{
+		{ID: rarityCommon, Prefix: "C", Name: "Common", Color: "#ffffff"},
+		{ID: rarityUncommon, Prefix: "U", Name: "Uncommon", Color: "#55ff55"},
+		{ID: rarityRare, Prefix: "R", Name: "Rare", Color: "#5555ff"},
+		{ID: rarityEpic, Prefix: "E", Name: "Epic", Color: "#aa00aa"},
+		{ID: rarityLegendary, Prefix: "L", Name: "Legendary", Color: "#ffaa00"},
+	}
+	defaultCategories = []categoryDef{
+		{ID: categoryForest, Name: "Forest"},
+		{ID: categoryWater, Name: "Water"},
+		{ID: categoryCombat, Name: "Combat"},
+	}
+	defaultScheme = &shardScheme{rarities: defaultRarities, categories: defaultCategories}
+)
+
+// shardScheme is the rarities and categories of one shard config. Every shard keeps a pointer to
+// it, so ordering and ID parsing don't need the config at hand.
+type shardScheme struct {
+	rarities   []rarityDef
+	categories []categoryDef
+}
+
+func newShardScheme(config *shardConfig) (*shardScheme, error) {
+	scheme := &shardScheme{rarities: config.Rarities, categories: config.Categories}
+	if len(scheme.rarities) == 0 {
+		scheme.rarities = defaultRarities
+	}
+	if len(scheme.categories) == 0 {
+		scheme.categories = defaultCategories
+	}
+
+	for i, r := range scheme.rarities {
+		switch {
+		case r.ID == "":
+			return nil, errorOf(ErrInvalidShardData, "rarity %d has no ID", i+1)
+		case r.Prefix == "" || strings.IndexFunc(r.Prefix, func(c rune) bool { return c < 'A' || c > 'Z' }) >= 0:
+			return nil, errorOf(ErrInvalidShardData, "rarity %s needs an upper case prefix, got %q", r.ID, r.Prefix)
+		case len(r.LevelCosts) == 0 && levelCostCurves[r.ID] == nil:
+			return nil, errorOf(ErrInvalidShardData, "rarity %s needs levelCosts", r.ID)
+		case len(r.LevelCosts) != 0 && len(r.LevelCosts) != MaxAttributeLevel:
+			return nil, errorOf(ErrInvalidShardData, "rarity %s needs %d levelCosts, got %d", r.ID, MaxAttributeLevel, len(r.LevelCosts))
+		}
+		for _, other := range scheme.rarities[:i] {
+			// A prefix that starts another one would make IDs like "LE1" ambiguous
+			if other.ID == r.ID || strings.HasPrefix(other.Prefix, r.Prefix) || strings.HasPrefix(r.Prefix, other.Prefix) {
+				return nil, errorOf(ErrInvalidShardData, "rarities %s and %s clash", other.ID, r.ID)
+			}
+		}
+	}
+	for i, c := range scheme.categories {
+		if c.ID == "" {
+			return nil, errorOf(ErrInvalidShardData, "category %d has no ID", i+1)
+		}
+		if slices.ContainsFunc(scheme.categories[:i], func(other categoryDef) bool { return other.ID == c.ID }) {
+			return nil, errorOf(ErrInvalidShardData, "category %s is declared twice", c.ID)
+		}
+	}
+	return scheme, nil
+}
+
+// Shards built outside of processing, like in tests, have no scheme and get the default one
+func (sc *shardScheme) orDefault() *shardScheme {
+	if sc == nil {
+		return defaultScheme
+	}
+	return sc
+}
+
+func (sc *shardScheme) rarityIndex(r rarity) int {
+	return slices.IndexFunc(sc.orDefault().rarities, func(def rarityDef) bool { return def.ID == r })
+}
+
+// rank orders rarities from 1 for the lowest, and 0 for unknown ones
+func (sc *shardScheme) rank(r rarity) int {
+	return sc.rarityIndex(r) + 1
+}
+
+func (sc *shardScheme) rarityDef(r rarity) rarityDef {
+	if i := sc.rarityIndex(r); i >= 0 {
+		return sc.orDefault().rarities[i]
+	}
+	return rarityDef{}
+}
+
+// RarityColor is the color the shard's rarity declares, like "#ffaa00", or "" if it has none
+func (s *Shard) RarityColor() string {
+	return s.scheme.rarityDef(s.Rarity).Color
+}
+
+// next is the rarity wildcard fusions roll over into, or "" for the highest
+func (sc *shardScheme) next(r rarity) rarity {
+	sc = sc.orDefault()
+	i := sc.rarityIndex(r)
+	if i < 0 || i+1 >= len(sc.rarities) {
+		return ""
+	}
+	return sc.rarities[i+1].ID
+}
+
+func (sc *shardScheme) shardID(r rarity, number int) string {
+	return sc.rarityDef(r).Prefix + strconv.Itoa(number)
+}
+
+func (sc *shardScheme) parseRarity(r string) (rarity, error) {
+	if sc.rarityIndex(rarity(r)) < 0 {
+		return "", errorOf(ErrInvalidShardData, "invalid rarity: %s", r)
+	}
+	return rarity(r), nil
+}
+
+func (sc *shardScheme) parseCategory(c string) (category, error) {
+	sc = sc.orDefault()
+	if !slices.ContainsFunc(sc.categories, func(def categoryDef) bool { return string(def.ID) == c }) {
+		return "", errorOf(ErrInvalidShardData, "invalid category: %s", c)
+	}
+	return category(c), nil
+}
+
+// parseID splits an ID like "R53" into its rarity and number
+func (sc *shardScheme) parseID(id string) (rarity, int, error) {
+	sc = sc.orDefault()
+	split := strings.IndexFunc(id, func(c rune) bool { return c < 'A' || c > 'Z' })
+	if split <= 0 || split == len(id) {
+		return "", 0, errorOf(ErrInvalidShardData, "invalid shard ID: %s", id)
+	}
+	i := slices.IndexFunc(sc.rarities, func(def rarityDef) bool { return def.Prefix == id[:split] })
+	if i < 0 {
+		return "", 0, errorOf(ErrInvalidShardData, "unknown rarity in shard ID: %s", id)
+	}
+	number, err := strconv.Atoi(id[split:])
+	if err != nil {
+		return "", 0, errorOf(ErrInvalidShardData, "invalid number in shard ID %s: %w", id, err)
+	}
+	return sc.rarities[i].ID, number, nil
+}
+
+// levelCosts returns the shards needed for each level so the total matches costToMax
+func (sc *shardScheme) levelCosts(costToMax map[string]int) map[rarity][]int {
+	sc = sc.orDefault()
+	levelCosts := make(map[rarity][]int, len(sc.rarities))
+	for _, r := range sc.rarities {
+		curve := r.LevelCosts
+		if len(curve) == 0 {
+			curve = levelCostCurves[r.ID]
+		}
+		levelCosts[r.ID] = scaleLevelCurve(curve, costToMax[string(r.ID)])
+	}
+	return levelCosts
+}
@@ -0,0 +1,56 @@
+package shards
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestConfigScheme(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	config.Rarities = append(slices.Clone(config.Rarities), rarityDef{
+		ID: "mythic", Prefix: "M", Name: "Mythic", LevelCosts: []int{1, 1, 1, 1, 2, 2, 2, 2, 3, 3},
+	})
+	config.Categories = append(slices.Clone(config.Categories), categoryDef{ID: "void", Name: "Void"})
+	mythic := config.Shards["C1"]
+	mythic.Category = "void"
+	mythic.SpecialFuses = []specialFuse{{
+		Requirement1: specialFuseRequirement{Expr: "rarity:legendary+"},
+		Requirement2: specialFuseRequirement{Expr: "category:void"},
+	}}
+	config.Shards["M1"] = mythic
+
+	shardData, err := processShardConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	if shardData.RarityRank("mythic") != 6 || shardData.Shards["M1"].Rarity != "mythic" {
+		t.Errorf("Expected M1 to be a mythic shard ranked above legendary")
+	}
+	if len(shardData.LevelCosts["mythic"]) != MaxAttributeLevel || len(shardData.CategoryShards["void"]) != 1 {
+		t.Errorf("Expected level costs and a category group for the new declarations")
+	}
+
+	// The last legendaries roll over into mythic for chameleon fusions
+	legendaries := shardData.RarityShards[rarityLegendary]
+	last := shardData.Shards[legendaries[len(legendaries)-1]]
+	if !slices.Contains(last.WildcardTargets["chameleon"], "M1") {
+		t.Errorf("Expected %s to roll over into M1, got %v", last.ID, last.WildcardTargets)
+	}
+	info := shardData.SpecialRequirementInfo["Rarity: legendary+"]
+	if info == nil || !slices.Contains(info.Matches, "M1") {
+		t.Errorf("Expected legendary+ to include mythic shards, got %+v", info)
+	}
+
+	config.Rarities[len(config.Rarities)-1].LevelCosts = nil
+	if _, err := processShardConfig(config); !errors.Is(err, ErrInvalidShardData) {
+		t.Errorf("Expected a rarity without level costs to be rejected, got %v", err)
+	}
+	config.Rarities[len(config.Rarities)-1] = rarityDef{ID: "mythic", Prefix: "L", Name: "Mythic", LevelCosts: []int{1, 1, 1, 1, 2, 2, 2, 2, 3, 3}}
+	if _, err := processShardConfig(config); !errors.Is(err, ErrInvalidShardData) {
+		t.Errorf("Expected a clashing prefix to be rejected, got %v", err)
+	}
+}
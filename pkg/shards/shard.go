@@ -16,16 +16,18 @@ const (
 	categoryCombat  category = "combat"
 )
 
-type shard struct {
+type Shard struct {
 	ID                string                     `json:"id"`
 	BazaarId          string                     `json:"bazaarId"`
 	Name              string                     `json:"name"`
+	Aliases           []string                   `json:"aliases,omitempty"`
 	Rarity            rarity                     `json:"rarity"`
 	Number            int                        `json:"number"`
 	AttributeName     string                     `json:"attributeName"`
 	EffectDescription string                     `json:"effectDescription"`
 	EffectMax         float64                    `json:"effectMax"`
 	Effect2Max        float64                    `json:"effect2Max,omitempty"`
+	Stats             []EffectStat               `json:"stats,omitempty"`
 	EffectTags        map[string]bool            `json:"effectTags,omitempty"`
 	Category          category                   `json:"category"`
 	Skill             string                     `json:"skill"`
@@ -35,8 +37,10 @@ type shard struct {
 	SpecialFuses      []specialFuse              `json:"specialFuses,omitempty"`
 	SpecialFusesDesc  [][]string                 `json:"specialFusesDesc,omitempty"`
 	BasicFuseTarget   string                     `json:"basicFuseTarget"`
-	ChameleonTargets  []string                   `json:"chameleonTargets,omitempty"`
-	FuseCombinations  map[string]fuseCombination `json:"fuseCombinations,omitempty"`
+	WildcardTargets   map[string][]string        `json:"wildcardTargets,omitempty"`
+	FuseCombinations  map[string]FuseCombination `json:"fuseCombinations,omitempty"`
+
+	scheme *shardScheme
 }
 
 type source struct {
@@ -50,82 +54,41 @@ type specialFuse struct {
 	Requirement2 specialFuseRequirement `json:"requirement2"`
 }
 
+// A requirement is written either as an object, where each listed field must match one of its
+// values, or as an expression string like "category:forest & !family:shulker"
 type specialFuseRequirement struct {
 	Rarity   []string `json:"rarity,omitempty"`
 	Category []string `json:"category,omitempty"`
 	Shard    []string `json:"shard,omitempty"`
 	Family   []string `json:"family,omitempty"`
+	// Expr is the expression form. It is marshaled as a bare string and excludes the fields above.
+	Expr string `json:"-"`
+
+	// Parsed when unmarshaling, so evaluating a requirement doesn't re-parse it every time
+	expr     *reqNode
+	parseErr error
 }
 
-type fuseCombination struct {
+type FuseCombination struct {
 	Shard1  string       `json:"shard1"`
 	Cost1   int          `json:"cost1"`
 	Shard2  string       `json:"shard2"`
 	Cost2   int          `json:"cost2"`
-	Results []fuseResult `json:"results"`
+	Results []FuseResult `json:"results"`
 }
 
-type fuseResult struct {
+type FuseResult struct {
 	Type       string `json:"type"`
 	ID         string `json:"id"`
 	Multiplier int    `json:"multiplier"`
 }
 
-func rarityValue(r rarity) int {
-	switch r {
-	case rarityCommon:
-		return 1
-	case rarityUncommon:
-		return 2
-	case rarityRare:
-		return 3
-	case rarityEpic:
-		return 4
-	case rarityLegendary:
-		return 5
-	default:
-		return 0 // Unknown rarity
-	}
-}
-
-func getRarityAbbreviation(r rarity) string {
-	switch r {
-	case rarityCommon:
-		return "C"
-	case rarityUncommon:
-		return "U"
-	case rarityRare:
-		return "R"
-	case rarityEpic:
-		return "E"
-	case rarityLegendary:
-		return "L"
-	default:
-		return ""
-	}
-}
-
-func getNextRarity(r rarity) rarity {
-	switch r {
-	case rarityCommon:
-		return rarityUncommon
-	case rarityUncommon:
-		return rarityRare
-	case rarityRare:
-		return rarityEpic
-	case rarityEpic:
-		return rarityLegendary
-	default:
-		return ""
-	}
-}
-
-func getSortedShards(shards map[string]*shard) []*shard {
-	sortedShards := make([]*shard, 0, len(shards))
+func getSortedShards(shards map[string]*Shard) []*Shard {
+	sortedShards := make([]*Shard, 0, len(shards))
 	for _, s := range shards {
 		sortedShards = append(sortedShards, s)
 	}
-	slices.SortFunc(sortedShards, func(a, b *shard) int {
+	slices.SortFunc(sortedShards, func(a, b *Shard) int {
 		sortNumA := getShardSortValue(a)
 		sortNumB := getShardSortValue(b)
 		return sortNumA - sortNumB
@@ -133,12 +96,12 @@ func getSortedShards(shards map[string]*shard) []*shard {
 	return sortedShards
 }
 
-func getShardSortValue(s *shard) int {
-	sortNum := s.Number + rarityValue(s.Rarity)*1000
+func getShardSortValue(s *Shard) int {
+	sortNum := s.Number + s.scheme.rank(s.Rarity)*1000
 	return sortNum
 }
 
-// func getShardIdSortValue(id string, shards map[string]*shard) int {
+// func getShardIdSortValue(id string, shards map[string]*Shard) int {
 // 	shard, exists := shards[id]
 // 	if !exists {
 // 		panic("Shard ID not found: " + id)
@@ -0,0 +1,323 @@
+package shards
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrGoalUnreachable = errors.New("stat goal can't be reached")
+
+// StatGoal is an amount of one stat to reach, written like the effect it matches: "+50 strength",
+// "+1% crit damage" or "+20 defense against spiders"
+type StatGoal struct {
+	Stat      string  `json:"stat"`
+	Unit      string  `json:"unit,omitempty"`
+	Condition string  `json:"condition,omitempty"`
+	Amount    float64 `json:"amount"`
+}
+
+func (g StatGoal) String() string {
+	s := "+" + strconv.FormatFloat(g.Amount, 'f', -1, 64) + g.Unit + " " + g.Stat
+	if g.Condition != "" {
+		s += " " + g.Condition
+	}
+	return s
+}
+
+func (g StatGoal) matches(stat EffectStat) bool {
+	return stat.Stat == g.Stat && stat.Unit == g.Unit && stat.Condition == g.Condition
+}
+
+// ParseStatGoals reads a comma separated list of goals like "+50 strength, +30 health"
+func ParseStatGoals(s string) ([]StatGoal, error) {
+	goals := make([]StatGoal, 0)
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		numEnd := strings.IndexFunc(part, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != '+'
+		})
+		if numEnd <= 0 {
+			return nil, fmt.Errorf("goal %q must start with an amount", part)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimPrefix(part[:numEnd], "+"), 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("goal %q must start with a positive amount", part)
+		}
+		// Reuse the effect grammar so goals split into stat and condition the same way
+		stats, ok := parseEffectStats("+{{effect}}"+part[numEnd:], amount, 0)
+		if !ok || len(stats) != 1 {
+			return nil, fmt.Errorf("goal %q is not a single stat like \"+50 strength\"", part)
+		}
+		stat := stats[0]
+		goals = append(goals, StatGoal{Stat: stat.Stat, Unit: stat.Unit, Condition: stat.Condition, Amount: amount})
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("no stat goals given")
+	}
+	return goals, nil
+}
+
+type PlannedUpgrade struct {
+	ID        string  `json:"id"`
+	FromLevel int     `json:"fromLevel"`
+	ToLevel   int     `json:"toLevel"`
+	Shards    int     `json:"shards"`
+	UnitPrice float64 `json:"unitPrice"`
+	Fused     bool    `json:"fused"`
+	Cost      float64 `json:"cost"`
+	// Stat added towards each goal, in the order of the goals
+	Gains []float64 `json:"gains"`
+}
+
+type GoalPlan struct {
+	Goals    []StatGoal       `json:"goals"`
+	Upgrades []PlannedUpgrade `json:"upgrades"`
+	Cost     float64          `json:"cost"`
+	// Stat reached for each goal once the plan is done, counting levels already owned
+	Achieved []float64 `json:"achieved"`
+}
+
+// goalCandidate is a priced shard whose attribute counts towards at least one goal
+type goalCandidate struct {
+	shard *Shard
+	unit  unitPrice
+	from  int
+	// The shard's stats that count towards each goal
+	stats [][]EffectStat
+}
+
+func (c *goalCandidate) gain(goal int, from int, to int) float64 {
+	gain := 0.0
+	for _, stat := range c.stats[goal] {
+		gain += stat.At(to) - stat.At(from)
+	}
+	return gain
+}
+
+func (c *goalCandidate) cost(d *ProcessedShardData, from int, to int) float64 {
+	shards := 0
+	for _, n := range d.LevelCosts[c.shard.Rarity][from:to] {
+		shards += n
+	}
+	return float64(shards) * c.unit.price
+}
+
+// PlanStatGoals finds a cheap set of attribute upgrades that reaches every goal. A single goal is
+// solved exactly; several goals are solved greedily by stat per coin and then trimmed, which is
+// usually but not always the cheapest mix. Levels are keyed by shard ID, prices by bazaar ID.
+func (d *ProcessedShardData) PlanStatGoals(goals []StatGoal, levels map[string]int, prices map[string]float64) (*GoalPlan, error) {
+	unitPrices := d.unitPrices(prices)
+	remaining := make([]float64, len(goals))
+	for i, g := range goals {
+		remaining[i] = g.Amount
+	}
+
+	candidates := make([]*goalCandidate, 0)
+	for _, s := range getSortedShards(d.Shards) {
+		stats := make([][]EffectStat, len(goals))
+		useful := false
+		for i, g := range goals {
+			for _, stat := range s.Stats {
+				if g.matches(stat) {
+					stats[i] = append(stats[i], stat)
+					useful = true
+				}
+			}
+		}
+		if !useful {
+			continue
+		}
+		c := &goalCandidate{shard: s, from: levels[s.ID], stats: stats}
+		for i := range goals {
+			remaining[i] -= c.gain(i, 0, c.from)
+		}
+		unit, priced := unitPrices[s.ID]
+		if priced && c.from < MaxAttributeLevel {
+			c.unit = unit
+			candidates = append(candidates, c)
+		}
+	}
+
+	shortfall := make([]string, 0)
+	for i, g := range goals {
+		reachable := 0.0
+		for _, c := range candidates {
+			reachable += c.gain(i, c.from, MaxAttributeLevel)
+		}
+		if reachable < remaining[i]-1e-9 {
+			shortfall = append(shortfall, fmt.Sprintf("%s (at most %g more)", g, reachable))
+		}
+	}
+	if len(shortfall) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrGoalUnreachable, strings.Join(shortfall, ", "))
+	}
+
+	var targets []int
+	if len(goals) == 1 {
+		targets = d.planSingleGoal(candidates, remaining[0])
+	} else {
+		targets = d.planGreedy(candidates, remaining, goals)
+	}
+
+	plan := &GoalPlan{Goals: goals, Achieved: make([]float64, len(goals))}
+	for i, g := range goals {
+		plan.Achieved[i] = g.Amount - remaining[i]
+	}
+	for i, c := range candidates {
+		if targets[i] == c.from {
+			continue
+		}
+		upgrade := PlannedUpgrade{
+			ID:        c.shard.ID,
+			FromLevel: c.from,
+			ToLevel:   targets[i],
+			UnitPrice: c.unit.price,
+			Fused:     c.unit.fused,
+			Cost:      c.cost(d, c.from, targets[i]),
+			Gains:     make([]float64, len(goals)),
+		}
+		for _, n := range d.LevelCosts[c.shard.Rarity][c.from:targets[i]] {
+			upgrade.Shards += n
+		}
+		for g := range goals {
+			upgrade.Gains[g] = c.gain(g, c.from, targets[i])
+			plan.Achieved[g] += upgrade.Gains[g]
+		}
+		plan.Upgrades = append(plan.Upgrades, upgrade)
+		plan.Cost += upgrade.Cost
+	}
+	slices.SortFunc(plan.Upgrades, func(a, b PlannedUpgrade) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), cmp.Compare(a.ID, b.ID))
+	})
+	return plan, nil
+}
+
+// Stat amounts are compared in hundredths, which covers every effect step in the data
+const goalResolution = 100
+
+// planSingleGoal is a grouped knapsack over stat reached so far, capped at the goal. Each shard
+// is a group whose options are its levels, so the result is the exact cheapest mix.
+func (d *ProcessedShardData) planSingleGoal(candidates []*goalCandidate, need float64) []int {
+	targets := make([]int, len(candidates))
+	for i, c := range candidates {
+		targets[i] = c.from
+	}
+	target := int(math.Ceil(need*goalResolution - 1e-6))
+	if target <= 0 {
+		return targets
+	}
+
+	best := make([]float64, target+1)
+	for amount := range best {
+		best[amount] = math.Inf(1)
+	}
+	best[0] = 0
+	// For each shard and amount reached after it: the level it went to and the amount before it
+	levelTo := make([][]int8, len(candidates))
+	previous := make([][]int32, len(candidates))
+	for i, c := range candidates {
+		next := slices.Clone(best)
+		levelTo[i] = make([]int8, target+1)
+		previous[i] = make([]int32, target+1)
+		for amount := range next {
+			levelTo[i][amount] = int8(c.from)
+			previous[i][amount] = int32(amount)
+		}
+		for to := c.from + 1; to <= MaxAttributeLevel; to++ {
+			gain := int(math.Round(c.gain(0, c.from, to) * goalResolution))
+			cost := c.cost(d, c.from, to)
+			for amount, base := range best {
+				reached := min(target, amount+gain)
+				if base+cost < next[reached] {
+					next[reached] = base + cost
+					levelTo[i][reached] = int8(to)
+					previous[i][reached] = int32(amount)
+				}
+			}
+		}
+		best = next
+	}
+
+	amount := target
+	for i := len(candidates) - 1; i >= 0; i-- {
+		targets[i] = int(levelTo[i][amount])
+		amount = int(previous[i][amount])
+	}
+	return targets
+}
+
+// planGreedy keeps buying whichever level jump covers the most of the unmet goals per coin, each
+// goal weighted by its size, then drops levels that turned out not to be needed
+func (d *ProcessedShardData) planGreedy(candidates []*goalCandidate, remaining []float64, goals []StatGoal) []int {
+	targets := make([]int, len(candidates))
+	for i, c := range candidates {
+		targets[i] = c.from
+	}
+	unmet := slices.Clone(remaining)
+	covered := func() bool {
+		return !slices.ContainsFunc(unmet, func(n float64) bool { return n > 1e-9 })
+	}
+
+	for !covered() {
+		bestScore, bestCandidate, bestTo := 0.0, -1, 0
+		for i, c := range candidates {
+			for to := targets[i] + 1; to <= MaxAttributeLevel; to++ {
+				useful := 0.0
+				for g := range goals {
+					useful += min(c.gain(g, targets[i], to), max(unmet[g], 0)) / goals[g].Amount
+				}
+				if score := useful / c.cost(d, targets[i], to); score > bestScore {
+					bestScore, bestCandidate, bestTo = score, i, to
+				}
+			}
+		}
+		if bestCandidate < 0 {
+			break
+		}
+		c := candidates[bestCandidate]
+		for g := range goals {
+			unmet[g] -= c.gain(g, targets[bestCandidate], bestTo)
+		}
+		targets[bestCandidate] = bestTo
+	}
+
+	// Levels bought early can be made redundant by later ones; give back the most expensive first
+	for trimmed := true; trimmed; {
+		trimmed = false
+		order := make([]int, 0, len(candidates))
+		for i, c := range candidates {
+			if targets[i] > c.from {
+				order = append(order, i)
+			}
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			return cmp.Compare(candidates[b].cost(d, targets[b]-1, targets[b]), candidates[a].cost(d, targets[a]-1, targets[a]))
+		})
+		for _, i := range order {
+			c := candidates[i]
+			stillMet := true
+			for g := range goals {
+				if unmet[g]+c.gain(g, targets[i]-1, targets[i]) > 1e-9 {
+					stillMet = false
+				}
+			}
+			if stillMet {
+				for g := range goals {
+					unmet[g] += c.gain(g, targets[i]-1, targets[i])
+				}
+				targets[i]--
+				trimmed = true
+				break
+			}
+		}
+	}
+	return targets
+}
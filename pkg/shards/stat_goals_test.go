@@ -0,0 +1,66 @@
+package shards
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanStatGoals(t *testing.T) {
+	shardData, err := ProcessShards(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to process shard data: %v", err)
+	}
+	prices := make(map[string]float64)
+	for _, s := range shardData.Shards {
+		prices[s.BazaarId] = 1000
+	}
+
+	goals, err := ParseStatGoals("+20 strength")
+	if err != nil {
+		t.Fatalf("Failed to parse goal: %v", err)
+	}
+	plan, err := shardData.PlanStatGoals(goals, nil, prices)
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	if plan.Achieved[0] < 20 {
+		t.Errorf("Plan only reaches %g strength", plan.Achieved[0])
+	}
+
+	// The exact single goal plan can never lose to the greedy one
+	candidates := make([]*goalCandidate, 0)
+	for _, s := range getSortedShards(shardData.Shards) {
+		if len(s.Stats) > 0 && goals[0].matches(s.Stats[0]) {
+			candidates = append(candidates, &goalCandidate{shard: s, unit: unitPrice{price: 1000}, stats: [][]EffectStat{s.Stats[:1]}})
+		}
+	}
+	greedyCost := 0.0
+	for i, to := range shardData.planGreedy(candidates, []float64{20}, goals) {
+		greedyCost += candidates[i].cost(shardData, 0, to)
+	}
+	if plan.Cost > greedyCost {
+		t.Errorf("Exact plan costs %g, more than the greedy %g", plan.Cost, greedyCost)
+	}
+
+	owned, err := shardData.PlanStatGoals(goals, map[string]int{"L3": 10}, prices)
+	if err != nil || owned.Cost >= plan.Cost {
+		t.Errorf("Owning L3 should make the goal cheaper, got %v, %v", owned, err)
+	}
+
+	goals, err = ParseStatGoals("+20 strength, +100 health")
+	if err != nil {
+		t.Fatalf("Failed to parse goals: %v", err)
+	}
+	plan, err = shardData.PlanStatGoals(goals, nil, prices)
+	if err != nil || plan.Achieved[0] < 20 || plan.Achieved[1] < 100 {
+		t.Errorf("Expected both goals to be met, got %+v, %v", plan, err)
+	}
+
+	goals, _ = ParseStatGoals("+1000 strength")
+	if _, err := shardData.PlanStatGoals(goals, nil, prices); !errors.Is(err, ErrGoalUnreachable) {
+		t.Errorf("Expected an unreachable goal, got %v", err)
+	}
+	if _, err := ParseStatGoals("strength"); err == nil {
+		t.Errorf("Expected a goal without an amount to fail")
+	}
+}
@@ -0,0 +1,149 @@
+package shards
+
+import "slices"
+
+const (
+	// Fusion results are ordered by priority, lowest first. Wildcards pick their own priority
+	// relative to these, and go first on a tie.
+	basicFusePriority   = 2
+	specialFusePriority = 3
+
+	rolloverNone       = ""
+	rolloverNextRarity = "nextRarity"
+)
+
+// wildcardConfig declares a shard that fuses with any other shard into the shards numbered just
+// after it, like the Chameleon
+type wildcardConfig struct {
+	Shard string `json:"shard"`
+	// Fuse type of the results, like "chameleon"
+	Type string `json:"type"`
+	// Targets are looked for at these offsets from the other shard's number, in its rarity
+	OffsetMin int `json:"offsetMin"`
+	OffsetMax int `json:"offsetMax"`
+	Count     int `json:"count"`
+	// "nextRarity" makes up for missing targets from the first numbers of the next rarity
+	Rollover string `json:"rollover,omitempty"`
+	// Only shards in these categories can be targets. Empty means any category.
+	Categories []string `json:"categories,omitempty"`
+	// Only shards in the other shard's category can be targets
+	SameCategory bool `json:"sameCategory,omitempty"`
+	Priority     int  `json:"priority"`
+}
+
+// Shard data written before wildcards were declared gets the Chameleon
+var defaultWildcards = []wildcardConfig{{
+	Shard:     "L4",
+	Type:      "chameleon",
+	OffsetMin: 1,
+	OffsetMax: 3,
+	Count:     3,
+	Rollover:  rolloverNextRarity,
+	Priority:  1,
+}}
+
+// wildcards returns the declared wildcards in priority order
+func (c *shardConfig) wildcards() []wildcardConfig {
+	if len(c.Wildcards) == 0 {
+		return defaultWildcards
+	}
+	wildcards := slices.Clone(c.Wildcards)
+	slices.SortStableFunc(wildcards, func(a, b wildcardConfig) int {
+		return a.Priority - b.Priority
+	})
+	return wildcards
+}
+
+func validateWildcards(config *shardConfig, scheme *shardScheme) error {
+	types := map[string]bool{"basic": true, "special": true}
+	for _, w := range config.wildcards() {
+		_, exists := config.Shards[w.Shard]
+		switch {
+		case !exists:
+			return errorOf(ErrInvalidShardData, "%w: wildcard %s", ErrUnknownShard, w.Shard)
+		case w.Type == "" || types[w.Type]:
+			return errorOf(ErrInvalidShardData, "wildcard %s needs a fuse type of its own, got %q", w.Shard, w.Type)
+		case w.OffsetMin < 1 || w.OffsetMax < w.OffsetMin:
+			return errorOf(ErrInvalidShardData, "wildcard %s has invalid offsets %d to %d", w.Shard, w.OffsetMin, w.OffsetMax)
+		case w.Count < 1:
+			return errorOf(ErrInvalidShardData, "wildcard %s needs a count of at least 1", w.Shard)
+		case w.Rollover != rolloverNone && w.Rollover != rolloverNextRarity:
+			return errorOf(ErrInvalidShardData, "wildcard %s has invalid rollover %q", w.Shard, w.Rollover)
+		}
+		for _, c := range w.Categories {
+			if _, err := scheme.parseCategory(c); err != nil {
+				return errorOf(ErrInvalidShardData, "wildcard %s: %w", w.Shard, err)
+			}
+		}
+		types[w.Type] = true
+	}
+	return nil
+}
+
+// targets picks the shards the wildcard turns s into: the existing shards at each offset after s,
+// and, if that comes up short, the existing shards among the first few numbers of the next rarity
+func (w *wildcardConfig) targets(s *Shard, shards map[string]*Shard, scheme *shardScheme) []string {
+	targets := make([]string, 0, w.Count)
+	take := func(r rarity, number int) {
+		target, exists := shards[scheme.shardID(r, number)]
+		if !exists || len(targets) == w.Count {
+			return
+		}
+		if w.SameCategory && target.Category != s.Category {
+			return
+		}
+		if len(w.Categories) > 0 && !slices.Contains(w.Categories, string(target.Category)) {
+			return
+		}
+		targets = append(targets, target.ID)
+	}
+
+	for offset := w.OffsetMin; offset <= w.OffsetMax; offset++ {
+		take(s.Rarity, s.Number+offset)
+	}
+	if len(targets) == w.Count || w.Rollover != rolloverNextRarity {
+		return targets
+	}
+	if next := scheme.next(s.Rarity); next != "" {
+		for number := 1; number <= w.OffsetMax-w.OffsetMin+1; number++ {
+			take(next, number)
+		}
+	}
+	return targets
+}
+
+func addWildcardFusionTargets(shards map[string]*Shard, config *shardConfig, scheme *shardScheme) {
+	wildcards := config.wildcards()
+	for _, s := range shards {
+		s.WildcardTargets = make(map[string][]string, len(wildcards))
+		for _, w := range wildcards {
+			if targets := w.targets(s, shards, scheme); len(targets) > 0 {
+				s.WildcardTargets[w.Type] = targets
+			}
+		}
+	}
+}
+
+// appendWildcardResults adds the results of wildcards with a priority in (after, upTo]
+func (c *fuseCombiner) appendWildcardResults(results []FuseResult, s1 *Shard, s2 *Shard, after int, upTo int) []FuseResult {
+	for _, w := range c.wildcards {
+		if w.Priority <= after || w.Priority > upTo {
+			continue
+		}
+		other := s2
+		if s1.ID != w.Shard {
+			if s2.ID != w.Shard {
+				continue
+			}
+			other = s1
+		}
+		for _, target := range other.WildcardTargets[w.Type] {
+			results = append(results, FuseResult{
+				Type:       w.Type,
+				ID:         target,
+				Multiplier: 1,
+			})
+		}
+	}
+	return results
+}
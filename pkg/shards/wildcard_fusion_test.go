@@ -0,0 +1,71 @@
+package shards
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWildcardTargets(t *testing.T) {
+	shards := make(map[string]*Shard)
+	for _, id := range []string{"C1", "C2", "C3", "C4", "C5", "C7", "U1", "U2", "U3", "L1", "L2"} {
+		r, number, _ := defaultScheme.parseID(id)
+		shards[id] = &Shard{ID: id, Rarity: r, Number: number, Category: categoryForest}
+	}
+	shards["C5"].Category = categoryWater
+
+	chameleon := defaultWildcards[0]
+	noRollover := chameleon
+	noRollover.Rollover = rolloverNone
+	sameCategory := chameleon
+	sameCategory.SameCategory = true
+
+	tests := []struct {
+		name     string
+		wildcard wildcardConfig
+		shard    string
+		expected []string
+	}{
+		{"offsets", chameleon, "C1", []string{"C2", "C3", "C4"}},
+		// A gap leaves the range short, so the next rarity makes up the difference
+		{"gap", chameleon, "C4", []string{"C5", "C7", "U1"}},
+		{"end of rarity", chameleon, "C7", []string{"U1", "U2", "U3"}},
+		{"no rollover", noRollover, "C4", []string{"C5", "C7"}},
+		{"highest rarity", chameleon, "L2", []string{}},
+		{"same category", sameCategory, "C3", []string{"C4", "U1", "U2"}},
+	}
+	for _, tt := range tests {
+		got := tt.wildcard.targets(shards[tt.shard], shards, defaultScheme)
+		if !slices.Equal(got, tt.expected) {
+			t.Errorf("%s: expected %v for %s, got %v", tt.name, tt.expected, tt.shard, got)
+		}
+	}
+}
+
+func TestWildcardConfig(t *testing.T) {
+	config, err := loadShardConfig(testShardDataLocation)
+	if err != nil {
+		t.Fatalf("Failed to load shard config: %v", err)
+	}
+	// A second wildcard ranked after basic fuses, turning shards into the next shard
+	config.Wildcards = append(slices.Clone(config.Wildcards), wildcardConfig{
+		Shard: "C1", Type: "mimic", OffsetMin: 1, OffsetMax: 1, Count: 1, Priority: 3,
+	})
+	shardData, err := processShardConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to process shard config: %v", err)
+	}
+	combo := shardData.Shards["C1"].FuseCombinations["C2"]
+	types := make([]string, 0, len(combo.Results))
+	for _, result := range combo.Results {
+		types = append(types, result.Type)
+	}
+	if !slices.Contains(types, "mimic") || types[0] == "mimic" {
+		t.Errorf("Expected mimic results after the basic fuse, got %v", types)
+	}
+
+	config.Wildcards[1].Type = "basic"
+	if _, err := processShardConfig(config); !errors.Is(err, ErrInvalidShardData) {
+		t.Errorf("Expected a wildcard reusing a built in fuse type to be rejected, got %v", err)
+	}
+}